@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"strings"
 	"unicode"
+	"unsafe"
 
 	"github.com/goccy/go-reflect"
+	"go.uber.org/multierr"
 
 	"github.com/ClickHouse/ch-go/proto"
 )
@@ -14,92 +16,356 @@ import (
 var (
 	ErrInvalidType      = errors.New("got invalid type")
 	ErrGotNotStructType = errors.New("expected type struct")
+	ErrRowRejected      = errors.New("row rejected")
+	ErrNilRow           = errors.New("nil row")
+	ErrNoColumns        = errors.New("row has no columns")
 )
 
 type batch[T any] struct {
 	input      proto.Input
-	appenders  []appender
-	structInfo []reflect.StructField
+	appenders  []fieldAppender
+	checkers   []checker
+	structInfo []structField
+
+	// row is the copy of appended row, fields are read by offsets from its address.
+	// ptrRow is true if T is pointer to struct, then fields are read from the pointed struct.
+	row    T
+	ptrRow bool
+
+	// appendRow appends rows without reflection if T implements ColumnarRow or GeneratedRow.
+	appendRow func(v T, input proto.Input)
+
+	// jsonCols keep marshal errors of appended values.
+	jsonCols []namedJSONColumn
+}
+
+// check validates row before append. Columns are not changed.
+func (b *batch[T]) check(v T) error {
+	if len(b.checkers) == 0 {
+		return nil
+	}
+
+	refVal := reflect.Indirect(reflect.ValueNoEscapeOf(v))
+	for i, check := range b.checkers {
+		if check == nil {
+			continue
+		}
+
+		field := b.structInfo[i]
+		if err := check(field.value(refVal).Interface()); err != nil {
+			return fmt.Errorf("%w: field %s: %v", ErrRowRejected, field.Name, err)
+		}
+	}
+	return nil
 }
 
-func (b *batch[T]) append(v T) {
-	for i, field := range fieldsToSlice(reflect.ValueNoEscapeOf(v), b.structInfo) {
-		b.appenders[i](field, b.input)
+// append appends row to columns. Values which pass check, but fail to marshal
+// to JSON on append are appended as null and reported by error.
+func (b *batch[T]) append(v T) error {
+	if b.appendRow != nil {
+		b.appendRow(v, b.input)
+		return nil
+	}
+
+	// Row is copied to the batch, so taking its address doesn't move v to heap.
+	b.row = v
+	row := unsafe.Pointer(&b.row)
+	if b.ptrRow {
+		row = *(*unsafe.Pointer)(row)
 	}
+
+	for i, field := range b.structInfo {
+		b.appenders[i](field.pointer(row), b.input)
+	}
+
+	// Values referenced by the row are not retained by batch.
+	var zero T
+	b.row = zero
+
+	var err error
+	for _, json := range b.jsonCols {
+		if colErr := json.col.takeErr(); colErr != nil {
+			err = multierr.Append(err, fmt.Errorf("column %s: value is appended as null: %w", json.name, colErr))
+		}
+	}
+	return err
 }
 
+// newBatch creates batch for rows of type T, which is struct or pointer to struct.
 func newBatch[T any]() (*batch[T], error) {
-	var v T
-	refVal := reflect.Indirect(reflect.ValueOf(v))
+	return newBatchWithNaming[T](columnNaming{})
+}
+
+// newBatchWithNaming creates batch which columns are named by naming.
+// Columns of ColumnarRow and GeneratedRow are named by the row type.
+func newBatchWithNaming[T any](naming columnNaming) (*batch[T], error) {
+	if input, ok := columnarRow[T](); ok {
+		if len(input) == 0 {
+			return nil, ErrNoColumns
+		}
+
+		return &batch[T]{
+			input:     input,
+			appendRow: appendColumnarRow[T],
+		}, nil
+	}
+
+	if gen, ok := generatedRow[T](); ok {
+		return &batch[T]{
+			input:     gen.ChdistrColumns(),
+			appendRow: gen.ChdistrAppend,
+		}, nil
+	}
+
+	// Type is resolved from T itself, since zero value of interface or pointer is nil.
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	ptrRow := typ.Kind() == reflect.Ptr
+	if ptrRow {
+		typ = typ.Elem()
+	}
 
 	var (
-		appenders  []appender
+		appenders  []fieldAppender
+		checkers   []checker
 		input      proto.Input
-		structInfo []reflect.StructField
+		structInfo []structField
 	)
-	switch refVal.Kind() {
+	switch typ.Kind() {
 	case reflect.Struct:
-		structInfo = getStructInfo(refVal)
-		for i, field := range structInfo {
-			var name string
-			if tagVal, ok := field.Tag.Lookup("ch"); !ok {
-				name = toUnderScore(field.Name)
+		if err := checkEmbeddedTags(typ, map[reflect.Type]struct{}{typ: {}}); err != nil {
+			return nil, fmt.Errorf("get input column and appender: %w", err)
+		}
+
+		structInfo = getStructInfo(reflect.New(typ).Elem(), naming)
+		names := make(map[string]struct{}, len(structInfo))
+		for _, field := range structInfo {
+			var (
+				cols     []proto.InputColumn
+				appender appender
+				direct   fieldAppender
+				checker  checker
+				err      error
+			)
+			if _, opts := parseTag(field.Tag.Get("ch")); opts.Has("nested") {
+				cols, appender, checker, err = getNestedColsAndAppender(field.column, len(input), field.StructField, naming)
 			} else {
-				name = tagVal
+				var col proto.InputColumn
+				col, appender, checker, direct, err = getColAndAppenderFromField(field.column, len(input), field.StructField, naming)
+				if err == nil {
+					err = checkTagOptions(field.StructField, col.Data.Type())
+				}
+				cols = []proto.InputColumn{col}
 			}
-
-			col, appender, err := getColAndAppenderFromField(name, i, field)
 			if err != nil {
-				return nil, fmt.Errorf("get input column and appender: %s", err)
+				return nil, fmt.Errorf("get input column and appender: %w", err)
 			}
 
-			input = append(input, col)
-			appenders = append(appenders, appender)
+			if direct == nil {
+				direct = newReflectFieldAppender(field.Type, appender)
+			}
+
+			for _, col := range cols {
+				if _, ok := names[col.Name]; ok {
+					return nil, fmt.Errorf("field %s: duplicate column %s", field.Name, col.Name)
+				}
+				names[col.Name] = struct{}{}
+			}
+
+			input = append(input, cols...)
+			appenders = append(appenders, direct)
+			checkers = append(checkers, checker)
 		}
-	case reflect.Invalid:
+
+		if !hasCheckers(checkers) {
+			checkers = nil
+		}
+	case reflect.Interface:
 		return nil, ErrInvalidType
 	default:
 		return nil, ErrGotNotStructType
 	}
 
+	var jsonCols []namedJSONColumn
+	for _, col := range input {
+		jsonCols = appendJSONColumns(jsonCols, col.Name, col.Data)
+	}
+
 	return &batch[T]{
 		input:      input,
 		appenders:  appenders,
+		checkers:   checkers,
 		structInfo: structInfo,
+		ptrRow:     ptrRow,
+		jsonCols:   jsonCols,
 	}, nil
 }
 
-func getStructInfo(v reflect.Value) []reflect.StructField {
-	info := make([]reflect.StructField, 0, v.NumField())
+// isNilRow reports whether v is nil pointer to struct.
+func isNilRow[T any](v T) bool {
+	refVal := reflect.ValueNoEscapeOf(v)
+	return refVal.Kind() == reflect.Ptr && refVal.IsNil()
+}
+
+func hasCheckers(checkers []checker) bool {
+	for _, check := range checkers {
+		if check != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// structField is the struct field mapped to the column.
+type structField struct {
+	reflect.StructField
+
+	column string
+	// offsets of the field in row, pointers to embedded structs are dereferenced between them.
+	offsets []uintptr
+}
+
+// pointer returns pointer to the field of struct at row
+// or nil if the field is promoted through nil embedded pointer.
+func (f structField) pointer(row unsafe.Pointer) unsafe.Pointer {
+	for i, offset := range f.offsets {
+		if i > 0 {
+			if row = *(*unsafe.Pointer)(row); row == nil {
+				return nil
+			}
+		}
+		row = unsafe.Add(row, offset)
+	}
+	return row
+}
+
+// value returns the field of struct v. Unlike reflect.Value.FieldByIndex,
+// it returns zero value if the field is promoted through nil embedded pointer.
+func (f structField) value(v reflect.Value) reflect.Value {
+	for i, x := range f.Index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Zero(f.Type)
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// getStructInfo returns fields of struct which are mapped to columns.
+// Fields of embedded structs are flattened, their column names are
+// prefixed with the prefix tag option of embedded field (e.g. `ch:",prefix=meta_"`).
+// Fields without name in ch tag are named by naming.
+func getStructInfo(v reflect.Value, naming columnNaming) []structField {
 	typeInfo := v.Type()
-	for i := 0; i < v.NumField(); i++ {
-		field := typeInfo.Field(i)
+	return appendStructInfo(
+		make([]structField, 0, v.NumField()),
+		typeInfo, nil, []uintptr{0}, "",
+		map[reflect.Type]struct{}{typeInfo: {}},
+		naming,
+	)
+}
+
+func appendStructInfo(
+	info []structField,
+	typ reflect.Type,
+	index []int,
+	offsets []uintptr,
+	prefix string,
+	visited map[reflect.Type]struct{},
+	naming columnNaming,
+) []structField {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
 		if fieldIsPrivate(field) {
 			continue
 		}
 
-		if tagVal, ok := field.Tag.Lookup("ch"); ok && tagVal == "-" {
+		name, opts := parseTag(field.Tag.Get("ch"))
+		if name == "-" {
 			continue
 		}
 
-		if len(field.Index) != 1 {
-			continue
+		field.Index = append(index[:len(index):len(index)], i)
+		fieldOffsets := append([]uintptr(nil), offsets...)
+		fieldOffsets[len(fieldOffsets)-1] += field.Offset
+
+		if embedded := embeddedStruct(field); embedded != nil && name == "" {
+			if _, ok := visited[embedded]; !ok {
+				visited[embedded] = struct{}{}
+				embeddedPrefix, _ := opts.Get("prefix")
+				embeddedOffsets := fieldOffsets
+				if field.Type.Kind() == reflect.Ptr {
+					embeddedOffsets = append(embeddedOffsets, 0)
+				}
+				info = appendStructInfo(info, embedded, field.Index, embeddedOffsets, prefix+embeddedPrefix, visited, naming)
+				delete(visited, embedded)
+				continue
+			}
+		}
+
+		if name == "" {
+			if name = naming.name(field); name == "-" {
+				continue
+			}
 		}
 
-		info = append(info, field)
+		info = append(info, structField{
+			StructField: field,
+			column:      prefix + name,
+			offsets:     fieldOffsets,
+		})
 	}
 	return info
 }
 
-func fieldsToSlice(v reflect.Value, structInfo []reflect.StructField) []any {
-	sample := make([]any, len(structInfo))
+// checkEmbeddedTags returns error for tag options of embedded structs other than prefix,
+// since fields of embedded structs are promoted and the struct has no column.
+func checkEmbeddedTags(typ reflect.Type, visited map[reflect.Type]struct{}) error {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		embedded := embeddedStruct(field)
+		if fieldIsPrivate(field) || embedded == nil {
+			continue
+		}
+
+		name, opts := parseTag(field.Tag.Get("ch"))
+		if _, ok := visited[embedded]; ok || name != "" {
+			continue
+		}
 
-	for i := 0; i < len(structInfo); i++ {
-		field := structInfo[i]
-		sample[i] = v.Field(field.Index[0]).Interface()
+		for opt := range opts {
+			if opt != "prefix" {
+				return fmt.Errorf("field %s: tag option %s is not supported for embedded struct", field.Name, opt)
+			}
+		}
+
+		visited[embedded] = struct{}{}
+		err := checkEmbeddedTags(embedded, visited)
+		delete(visited, embedded)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// embeddedStruct returns type of embedded struct (or pointer to struct) which fields are promoted.
+func embeddedStruct(field reflect.StructField) reflect.Type {
+	if !field.Anonymous {
+		return nil
 	}
 
-	return sample
+	typ := field.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if !isPlainStruct(typ) {
+		return nil
+	}
+	return typ
 }
 
 func fieldIsPrivate(field reflect.StructField) bool {
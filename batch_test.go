@@ -1,8 +1,10 @@
 package chdistr
 
 import (
+	"database/sql"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/ClickHouse/ch-go/proto"
 	"github.com/goccy/go-reflect"
@@ -10,12 +12,17 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestFieldsToSlice(t *testing.T) {
+func TestStructFieldPointer(t *testing.T) {
+	type Embedded struct {
+		F5 string
+	}
+
 	foo := struct {
 		F1 int
 		f2 string
 		F3 bool
 		F4 float32 `ch:"-"`
+		*Embedded
 	}{
 		F1: 11,
 		f2: "f2",
@@ -23,10 +30,36 @@ func TestFieldsToSlice(t *testing.T) {
 		F4: 1.01,
 	}
 
-	v := reflect.ValueOf(foo)
+	info := getStructInfo(reflect.ValueOf(foo), columnNaming{})
+	if !assert.Len(t, info, 3) {
+		return
+	}
+
+	row := unsafe.Pointer(&foo)
+	assert.Equal(t, 11, *(*int)(info[0].pointer(row)))
+	assert.Equal(t, true, *(*bool)(info[1].pointer(row)))
+	assert.True(t, info[2].pointer(row) == nil)
 
-	res := fieldsToSlice(v, getStructInfo(v))
-	assert.Equal(t, []any{11, true}, res)
+	foo.Embedded = &Embedded{F5: "f5"}
+	assert.Equal(t, "f5", *(*string)(info[2].pointer(row)))
+}
+
+// columnTypes asserts that each column of input has rows and returns types of columns.
+func columnTypes(t *testing.T, input proto.Input, rows int) []proto.ColumnType {
+	t.Helper()
+
+	types := make([]proto.ColumnType, 0, len(input))
+	for _, inp := range input {
+		assert.Equal(t, rows, inp.Data.Rows(), inp.Name)
+		types = append(types, inp.Data.Type())
+	}
+	return types
+}
+
+// newBatchErr returns error of newBatch, so invalid row types can be listed in test tables.
+func newBatchErr[T any]() error {
+	_, err := newBatch[T]()
+	return err
 }
 
 type testFoo struct {
@@ -81,14 +114,676 @@ func TestBatch(t *testing.T) {
 	}
 }
 
+func TestBatchPointerRow(t *testing.T) {
+	b, err := newBatch[*testNullable]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	s := "s"
+	row := &testNullable{S: &s}
+	assert.Nil(t, b.check(row))
+	b.append(row)
+	columnTypes(t, b.input, 1)
+	assert.Equal(t, "s", b.input[0].Data.(*nullableColumn).Values.(*valueColumn).ColInput.(*proto.ColStr).Row(0))
+
+	assert.True(t, isNilRow[*testNullable](nil))
+	assert.False(t, isNilRow(row))
+	assert.False(t, isNilRow(testNullable{}))
+
+	_, err = newBatch[any]()
+	assert.ErrorIs(t, err, ErrInvalidType)
+
+	_, err = newBatch[*int]()
+	assert.ErrorIs(t, err, ErrGotNotStructType)
+}
+
 func BenchmarkBatchAppend(b *testing.B) {
 	bt, err := newBatch[testFoo]()
 	if err != nil {
 		b.Fatal(err)
 	}
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		bt.append(testFoo{})
 	}
 }
+
+type TestPrimitiveMeta struct {
+	Host string
+	Port uint16
+}
+
+type testPrimitive struct {
+	ID      testUserID
+	I       int
+	I8      int8
+	F64     float64
+	B       bool
+	S       string
+	LC      string `ch:",lowcardinality"`
+	Payload []byte
+	U128    proto.UInt128
+	UUID    uuid.UUID
+	*TestPrimitiveMeta
+}
+
+func TestBatchAppendPrimitive(t *testing.T) {
+	b, err := newBatch[testPrimitive]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	row := testPrimitive{
+		ID:                1,
+		I:                 -2,
+		I8:                3,
+		F64:               4.5,
+		B:                 true,
+		S:                 "s",
+		LC:                "lc",
+		Payload:           []byte("payload"),
+		U128:              proto.UInt128FromInt(6),
+		UUID:              uuid.New(),
+		TestPrimitiveMeta: &TestPrimitiveMeta{Host: "host", Port: 9000},
+	}
+	b.append(row)
+	b.append(testPrimitive{})
+
+	assert.Equal(t, uint64(1), b.input[0].Data.(*proto.ColUInt64).Row(0))
+	assert.Equal(t, int64(-2), b.input[1].Data.(*proto.ColInt64).Row(0))
+	assert.Equal(t, int8(3), b.input[2].Data.(*proto.ColInt8).Row(0))
+	assert.Equal(t, 4.5, b.input[3].Data.(*proto.ColFloat64).Row(0))
+	assert.Equal(t, true, b.input[4].Data.(*proto.ColBool).Row(0))
+	assert.Equal(t, "s", b.input[5].Data.(*proto.ColStr).Row(0))
+	assert.Equal(t, "lc", b.input[6].Data.(*proto.ColLowCardinality[string]).Row(0))
+	assert.Equal(t, "payload", b.input[7].Data.(*proto.ColStr).Row(0))
+	assert.Equal(t, proto.UInt128FromInt(6), b.input[8].Data.(*proto.ColUInt128).Row(0))
+	assert.Equal(t, row.UUID, b.input[9].Data.(*proto.ColUUID).Row(0))
+	assert.Equal(t, "host", b.input[10].Data.(*proto.ColStr).Row(0))
+	assert.Equal(t, uint16(9000), b.input[11].Data.(*proto.ColUInt16).Row(0))
+
+	// Fields promoted through nil embedded pointer are zero values.
+	assert.Equal(t, "", b.input[10].Data.(*proto.ColStr).Row(1))
+	assert.Equal(t, uint16(0), b.input[11].Data.(*proto.ColUInt16).Row(1))
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		b.append(row)
+	}))
+
+	ptrBatch, err := newBatch[*testPrimitive]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		ptrBatch.append(&row)
+	}))
+	assert.Equal(t, "host", ptrBatch.input[10].Data.(*proto.ColStr).Row(0))
+}
+
+func BenchmarkBatchAppendPrimitive(b *testing.B) {
+	bt, err := newBatch[testPrimitive]()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	row := testPrimitive{
+		ID:                1,
+		S:                 "s",
+		LC:                "lc",
+		Payload:           []byte("payload"),
+		TestPrimitiveMeta: &TestPrimitiveMeta{Host: "host"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bt.append(row)
+	}
+}
+
+func BenchmarkBatchAppendPrimitivePointer(b *testing.B) {
+	bt, err := newBatch[*testPrimitive]()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	row := &testPrimitive{ID: 1, S: "s", LC: "lc", Payload: []byte("payload")}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bt.append(row)
+	}
+}
+
+type testNullable struct {
+	S  *string
+	I  *int64
+	T  *time.Time
+	NS sql.NullString
+	NI sql.NullInt64
+	NT sql.NullTime
+}
+
+func TestBatchNullable(t *testing.T) {
+	b, err := newBatch[testNullable]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	s := "foo"
+	b.append(testNullable{})
+	b.append(testNullable{
+		S:  &s,
+		NS: sql.NullString{String: "bar", Valid: true},
+	})
+
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []proto.ColumnType{
+		"Nullable(String)",
+		"Nullable(Int64)",
+		"Nullable(DateTime)",
+		"Nullable(String)",
+		"Nullable(Int64)",
+		"Nullable(DateTime)",
+	}, types)
+
+	col := b.input[0].Data.(*nullableColumn)
+	assert.True(t, col.IsElemNull(0))
+	assert.False(t, col.IsElemNull(1))
+
+	col = b.input[3].Data.(*nullableColumn)
+	assert.True(t, col.IsElemNull(0))
+	assert.Equal(t, "bar", col.Values.(*valueColumn).ColInput.(*proto.ColStr).Row(1))
+}
+
+func TestBatchNestedNullable(t *testing.T) {
+	_, err := newBatch[struct{ S **string }]()
+	assert.ErrorIs(t, err, errNestedNullable)
+}
+
+type testArrays struct {
+	Tags   []string
+	IDs    []uint64
+	Matrix [][]int32
+	IPs    []proto.IPv4
+	UUIDs  []uuid.UUID
+	Opt    []*string
+	Bytes  []byte
+}
+
+func TestBatchArrays(t *testing.T) {
+	b, err := newBatch[testArrays]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b.append(testArrays{})
+	b.append(testArrays{
+		Tags:   []string{"a", "b"},
+		IDs:    []uint64{1},
+		Matrix: [][]int32{{1, 2}, {}, {3}},
+		IPs:    []proto.IPv4{1, 2, 3},
+		UUIDs:  []uuid.UUID{uuid.New()},
+		Opt:    []*string{nil},
+		Bytes:  []byte("bytes"),
+	})
+
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []proto.ColumnType{
+		"Array(String)",
+		"Array(UInt64)",
+		"Array(Array(Int32))",
+		"Array(IPv4)",
+		"Array(UUID)",
+		"Array(Nullable(String))",
+		"String",
+	}, types)
+
+	matrix := b.input[2].Data.(*proto.ColArr[reflect.Value])
+	assert.Equal(t, proto.ColUInt64{0, 3}, matrix.Offsets)
+	assert.Equal(t, proto.ColUInt64{2, 2, 3}, matrix.Data.(*valueColumn).ColInput.(*proto.ColArr[reflect.Value]).Offsets)
+}
+
+func TestBatchNullableArray(t *testing.T) {
+	_, err := newBatch[struct{ S *[]string }]()
+	assert.NotNil(t, err)
+}
+
+type testMaps struct {
+	Attrs    map[string]string `ch:"attrs,sorted"`
+	Counters map[string]uint64
+	Lists    map[uint8][]string
+}
+
+func TestBatchMaps(t *testing.T) {
+	b, err := newBatch[testMaps]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b.append(testMaps{})
+	b.append(testMaps{
+		Attrs:    map[string]string{"c": "3", "a": "1", "b": "2"},
+		Counters: map[string]uint64{"foo": 1},
+		Lists:    map[uint8][]string{1: {"a", "b"}},
+	})
+
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []proto.ColumnType{
+		"Map(String, String)",
+		"Map(String, UInt64)",
+		"Map(UInt8, Array(String))",
+	}, types)
+
+	assert.Equal(t, "attrs", b.input[0].Name)
+	attrs := b.input[0].Data.(*mapColumn)
+	keys := attrs.Keys.(*valueColumn).ColInput.(*proto.ColStr)
+	assert.Equal(t, []string{"a", "b", "c"}, []string{keys.Row(0), keys.Row(1), keys.Row(2)})
+}
+
+func TestBatchNullableMapKey(t *testing.T) {
+	_, err := newBatch[struct{ M map[*string]string }]()
+	assert.NotNil(t, err)
+}
+
+func TestBatchInvalidTagOptions(t *testing.T) {
+	type testEmbeddedOption struct {
+		TestMeta `ch:",lowcardinality"`
+	}
+
+	tests := []struct {
+		name     string
+		newBatch func() error
+		err      string
+	}{
+		{"typo", newBatchErr[struct {
+			S string `ch:"s,lowcardinalty"`
+		}], `unknown tag option "lowcardinalty"`},
+		{"bare option name", newBatchErr[struct {
+			S string `ch:"s,s"`
+		}], "tag option s is not supported for String"},
+		{"unit of non-Duration", newBatchErr[struct {
+			Latency int64 `ch:"latency,ms"`
+		}], "tag option ms is not supported for Int64"},
+		{"sorted non-map", newBatchErr[struct {
+			Tags []string `ch:"tags,sorted"`
+		}], "tag option sorted is not supported for Array(String)"},
+		{"tz of non-time", newBatchErr[struct {
+			Name string `ch:"name,tz=UTC"`
+		}], "tag option tz is not supported for String"},
+		{"prefix of field", newBatchErr[struct {
+			Name string `ch:"name,prefix=x_"`
+		}], "tag option prefix is not supported for String"},
+		{"option of embedded struct", newBatchErr[testEmbeddedOption], "tag option lowcardinality is not supported for embedded struct"},
+		{"option of tuple element", newBatchErr[struct {
+			Point struct {
+				X float64 `ch:"x,sorted"`
+			}
+		}], "tag option sorted is not supported for Float64"},
+		{"option of nested element", newBatchErr[struct {
+			Attrs []struct {
+				Key string `ch:"key,date"`
+			} `ch:"attrs,nested"`
+		}], "tag option date is not supported for String"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.newBatch(), tt.err)
+		})
+	}
+}
+
+type testMapsEncode struct {
+	Attrs  map[string]string     `ch:"attrs,lowcardinality,sorted"`
+	Status map[string]testStatus `ch:"status,type=Map(LowCardinality(String),Enum8('active' = 1, 'blocked' = 2)),sorted"`
+}
+
+func TestBatchMapEncode(t *testing.T) {
+	registerTestEnums(t)
+
+	b, err := newBatch[testMapsEncode]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b.append(testMapsEncode{
+		Attrs:  map[string]string{"os": "linux", "arch": "amd64"},
+		Status: map[string]testStatus{"a": testStatusBlocked, "b": testStatusActive},
+	})
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"Map(LowCardinality(String), LowCardinality(String))",
+		"Map(LowCardinality(String), Enum8('active' = 1, 'blocked' = 2))",
+	}, types)
+
+	// Columns of ch-go which are prepared by hand.
+	attrKeys, attrValues := proto.NewLowCardinality[string](new(proto.ColStr)), proto.NewLowCardinality[string](new(proto.ColStr))
+	attrKeys.AppendArr([]string{"arch", "os"})
+	attrValues.AppendArr([]string{"amd64", "linux"})
+	statusKeys, statusValues := proto.NewLowCardinality[string](new(proto.ColStr)), new(proto.ColEnum)
+	statusKeys.AppendArr([]string{"a", "b"})
+	statusValues.Values = []string{"blocked", "active"}
+	assert.Nil(t, statusValues.Infer("Enum8('deleted' = 1, 'active' = 2, 'blocked' = 3)"))
+	for _, col := range []proto.Preparable{attrKeys, attrValues, statusKeys, statusValues} {
+		assert.Nil(t, col.Prepare())
+	}
+
+	attrs := proto.NewMap[string, string](attrKeys, attrValues)
+	attrs.Offsets.Append(2)
+	status := proto.NewMap[string, string](statusKeys, statusValues)
+	status.Offsets.Append(2)
+
+	// Codes of enum are inferred from the table.
+	assert.Nil(t, b.input[1].Data.(proto.Inferable).Infer("Map(String, Enum8('deleted' = 1, 'active' = 2, 'blocked' = 3))"))
+
+	var buf, expected proto.Buffer
+	block := proto.Block{Columns: len(b.input), Rows: 1}
+	assert.Nil(t, block.EncodeRawBlock(&buf, 54451, b.input))
+	assert.Nil(t, block.EncodeRawBlock(&expected, 54451, proto.Input{
+		{Name: "attrs", Data: attrs},
+		{Name: "status", Data: status},
+	}))
+	assert.Equal(t, expected.Buf, buf.Buf)
+}
+
+type testLowCardinality struct {
+	Country  string         `ch:"country,lowcardinality"`
+	City     *string        `ch:"city,lowcardinality"`
+	Region   sql.NullString `ch:"region,lowcardinality"`
+	Browsers []string       `ch:"browsers,lowcardinality"`
+}
+
+func TestBatchLowCardinality(t *testing.T) {
+	b, err := newBatch[testLowCardinality]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	city := "Paris"
+	b.append(testLowCardinality{Country: "FR", City: &city, Browsers: []string{"firefox"}})
+	b.append(testLowCardinality{Country: "FR"})
+	b.append(testLowCardinality{Country: "DE", City: &city})
+
+	types := columnTypes(t, b.input, 3)
+	assert.Equal(t, []proto.ColumnType{
+		"LowCardinality(String)",
+		"LowCardinality(Nullable(String))",
+		"LowCardinality(Nullable(String))",
+		"Array(LowCardinality(String))",
+	}, types)
+
+	var buf proto.Buffer
+	block := proto.Block{Columns: len(b.input), Rows: 3}
+	assert.Nil(t, block.EncodeRawBlock(&buf, 54451, b.input))
+
+	col := b.input[1].Data.(*colLowCardinalityNullable[string])
+	assert.Equal(t, []int{1, 0, 1}, col.keys)
+	assert.Equal(t, proto.KeyUInt8, col.key)
+	assert.Equal(t, 2, col.index.Rows())
+	assert.Equal(t, "Paris", col.index.Row(1))
+}
+
+func TestBatchLowCardinalityNotSupported(t *testing.T) {
+	_, err := newBatch[struct {
+		I int64 `ch:"i,lowcardinality"`
+	}]()
+	assert.NotNil(t, err)
+}
+
+type TestMeta struct {
+	Host    string
+	Version uint32
+}
+
+type TestTrace struct {
+	TraceID string
+}
+
+type testEmbedded struct {
+	TestMeta
+	*TestTrace `ch:",prefix=trace_"`
+
+	Name  string
+	Point struct {
+		X float64
+		Y float64 `ch:"lat"`
+	}
+	Related []TestMeta
+}
+
+func TestBatchEmbedded(t *testing.T) {
+	b, err := newBatch[testEmbedded]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b.append(testEmbedded{TestMeta: TestMeta{Host: "localhost", Version: 1}})
+	b.append(testEmbedded{TestTrace: &TestTrace{TraceID: "id"}, Related: []TestMeta{{}, {}}})
+
+	var names []string
+	for _, inp := range b.input {
+		names = append(names, inp.Name)
+	}
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []string{"host", "version", "trace_trace_id", "name", "point", "related"}, names)
+	assert.Equal(t, []proto.ColumnType{
+		"String",
+		"UInt32",
+		"String",
+		"String",
+		"Tuple(x Float64, lat Float64)",
+		"Array(Tuple(host String, version UInt32))",
+	}, types)
+
+	traceIDs := b.input[2].Data.(*proto.ColStr)
+	assert.Equal(t, "", traceIDs.Row(0))
+	assert.Equal(t, "id", traceIDs.Row(1))
+}
+
+type testRecursive struct {
+	Children []testRecursive
+}
+
+func TestBatchRecursiveTuple(t *testing.T) {
+	_, err := newBatch[struct{ Node testRecursive }]()
+	assert.NotNil(t, err)
+}
+
+func TestBatchDuplicateColumn(t *testing.T) {
+	_, err := newBatch[struct {
+		TestMeta
+		Host string
+	}]()
+	assert.NotNil(t, err)
+}
+
+type testFixedStr struct {
+	Hash    [16]byte
+	Country [2]byte
+	Code    string   `ch:"code,fixedstring=3"`
+	Raw     []byte   `ch:"raw,fixedstring=2"`
+	Codes   []string `ch:"codes,fixedstring=2"`
+	Vector  [3]float32
+}
+
+func TestBatchFixedStr(t *testing.T) {
+	b, err := newBatch[testFixedStr]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b.append(testFixedStr{
+		Hash:    [16]byte{1, 2, 3},
+		Country: [2]byte{'F', 'R'},
+		Code:    "ab",
+		Raw:     []byte("abc"),
+		Codes:   []string{"a", "abc"},
+	})
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"FixedString(16)",
+		"FixedString(2)",
+		"FixedString(3)",
+		"FixedString(2)",
+		"Array(FixedString(2))",
+		"Array(Float32)",
+	}, types)
+
+	assert.Equal(t, []byte{1, 2, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, b.input[0].Data.(*proto.ColFixedStr).Row(0))
+	assert.Equal(t, []byte("FR"), b.input[1].Data.(*proto.ColFixedStr).Row(0))
+	assert.Equal(t, []byte("ab\x00"), b.input[2].Data.(*proto.ColFixedStr).Row(0))
+	assert.Equal(t, []byte("ab"), b.input[3].Data.(*proto.ColFixedStr).Row(0))
+}
+
+func TestBatchInvalidFixedStr(t *testing.T) {
+	_, err := newBatch[struct {
+		S string `ch:"s,fixedstring=0"`
+	}]()
+	assert.NotNil(t, err)
+}
+
+type testAttr struct {
+	Key   string
+	Value float64
+}
+
+type testNested struct {
+	ID    uint64
+	Attrs []testAttr  `ch:"attrs,nested"`
+	Refs  []*testAttr `ch:"refs,nested"`
+	Name  string
+}
+
+func TestBatchNested(t *testing.T) {
+	b, err := newBatch[testNested]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b.append(testNested{ID: 1})
+	b.append(testNested{
+		ID:    2,
+		Attrs: []testAttr{{"a", 1}, {"b", 2}},
+		Refs:  []*testAttr{nil, {"c", 3}},
+		Name:  "name",
+	})
+
+	names := make([]string, 0, len(b.input))
+	for _, inp := range b.input {
+		names = append(names, inp.Name)
+	}
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []string{"id", "attrs.key", "attrs.value", "refs.key", "refs.value", "name"}, names)
+	assert.Equal(t, []proto.ColumnType{
+		"UInt64",
+		"Array(String)",
+		"Array(Float64)",
+		"Array(String)",
+		"Array(Float64)",
+		"String",
+	}, types)
+
+	for _, inp := range b.input[1:5] {
+		assert.Equal(t, proto.ColUInt64{0, 2}, inp.Data.(*proto.ColArr[reflect.Value]).Offsets)
+	}
+	assert.Equal(t, "name", b.input[5].Data.(*proto.ColStr).Row(1))
+	values := b.input[4].Data.(*proto.ColArr[reflect.Value]).Data.(*valueColumn).ColInput
+	assert.Equal(t, &proto.ColFloat64{0, 3}, values)
+}
+
+func TestBatchInvalidNested(t *testing.T) {
+	_, err := newBatch[struct {
+		Attrs []string `ch:"attrs,nested"`
+	}]()
+	assert.NotNil(t, err)
+
+	_, err = newBatch[struct {
+		Attr testAttr `ch:"attr,nested"`
+	}]()
+	assert.NotNil(t, err)
+
+	_, err = newBatch[struct {
+		Attrs []testAttr `ch:"attrs,nested"`
+		Key   []string   `ch:"attrs.key"`
+	}]()
+	assert.NotNil(t, err)
+}
+
+type (
+	testUserID uint64
+	testRank   int8
+	testFlag   bool
+	testScore  float32
+	testName   string
+	testRaw    []byte
+	testCount  uint
+)
+
+type testNamed struct {
+	ID      testUserID
+	Level   testRank
+	Flag    testFlag
+	Score   testScore
+	Name    testName
+	Country testName `ch:"country,lowcardinality"`
+	Code    testName `ch:"code,fixedstring=2"`
+	Raw     testRaw
+	Count   testCount
+	Parent  *testUserID
+	Tags    []testName
+}
+
+func TestBatchNamedTypes(t *testing.T) {
+	b, err := newBatch[testNamed]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	parent := testUserID(7)
+	b.append(testNamed{
+		ID:      1,
+		Level:   -2,
+		Flag:    true,
+		Score:   0.5,
+		Name:    "name",
+		Country: "FR",
+		Code:    "USA",
+		Raw:     testRaw("raw"),
+		Count:   3,
+		Parent:  &parent,
+		Tags:    []testName{"a", "b"},
+	})
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"UInt64",
+		"Int8",
+		"Bool",
+		"Float32",
+		"String",
+		"LowCardinality(String)",
+		"FixedString(2)",
+		"String",
+		"UInt64",
+		"Nullable(UInt64)",
+		"Array(String)",
+	}, types)
+
+	assert.Equal(t, uint64(1), b.input[0].Data.(*proto.ColUInt64).Row(0))
+	assert.Equal(t, int8(-2), b.input[1].Data.(*proto.ColInt8).Row(0))
+	assert.Equal(t, true, b.input[2].Data.(*proto.ColBool).Row(0))
+	assert.Equal(t, float32(0.5), b.input[3].Data.(*proto.ColFloat32).Row(0))
+	assert.Equal(t, "name", b.input[4].Data.(*proto.ColStr).Row(0))
+	assert.Equal(t, "FR", b.input[5].Data.(*proto.ColLowCardinality[string]).Row(0))
+	assert.Equal(t, []byte("US"), b.input[6].Data.(*proto.ColFixedStr).Row(0))
+	assert.Equal(t, "raw", b.input[7].Data.(*proto.ColStr).Row(0))
+	assert.Equal(t, uint64(3), b.input[8].Data.(*proto.ColUInt64).Row(0))
+}
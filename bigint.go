@@ -0,0 +1,89 @@
+package chdistr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+var errIntOverflow = errors.New("integer overflow")
+
+// bigIntColumnTypes are big integer columns selected by tag option of the same name in lower case.
+var bigIntColumnTypes = []proto.ColumnType{
+	proto.ColumnTypeInt128,
+	proto.ColumnTypeInt256,
+	proto.ColumnTypeUInt128,
+	proto.ColumnTypeUInt256,
+}
+
+// getBigIntColAndAppender returns Int128, Int256, UInt128 or UInt256 column for big.Int
+// selected by tag option (e.g. `ch:"balance,uint256"`). Values which don't fit
+// in column are rejected by checker, pointers to big.Int are Nullable.
+func getBigIntColAndAppender(idx int, typ reflect.Type, opts tagOptions) (proto.ColInput, appender, checker, bool, error) {
+	var set []proto.ColumnType
+	for _, t := range bigIntColumnTypes {
+		if opts.Has(strings.ToLower(string(t))) {
+			set = append(set, t)
+		}
+	}
+
+	if typ != bigIntType || len(set) == 0 {
+		return nil, nil, nil, false, nil
+	}
+	if len(set) > 1 {
+		return nil, nil, nil, false, fmt.Errorf("conflicting tag options %s", strings.ToLower(fmt.Sprint(set)))
+	}
+
+	var (
+		colType = set[0]
+		bits    = 128
+		signed  = colType == proto.ColumnTypeInt128 || colType == proto.ColumnTypeInt256
+	)
+	if colType == proto.ColumnTypeInt256 || colType == proto.ColumnTypeUInt256 {
+		bits = 256
+	}
+
+	// Range of values is [min, max).
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	if signed {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+
+	value := func(v any) (*big.Int, error) {
+		i := v.(big.Int)
+		if i.Cmp(min) < 0 || i.Cmp(max) >= 0 {
+			return nil, fmt.Errorf("%w: %s doesn't fit in %s", errIntOverflow, &i, colType)
+		}
+		return &i, nil
+	}
+
+	var (
+		data  proto.ColInput
+		fn    appender
+		check checker
+	)
+	switch colType {
+	case proto.ColumnTypeInt128:
+		data = &proto.ColInt128{}
+		fn, check = newConvertingAppender(idx, convertBigInt(value, bigToInt128))
+	case proto.ColumnTypeInt256:
+		data = &proto.ColInt256{}
+		fn, check = newConvertingAppender(idx, convertBigInt(value, bigToInt256))
+	case proto.ColumnTypeUInt128:
+		data = &proto.ColUInt128{}
+		fn, check = newConvertingAppender(idx, convertBigInt(value, func(v *big.Int) proto.UInt128 {
+			return proto.UInt128(bigToInt128(v))
+		}))
+	default:
+		data = &proto.ColUInt256{}
+		fn, check = newConvertingAppender(idx, convertBigInt(value, func(v *big.Int) proto.UInt256 {
+			return proto.UInt256(bigToInt256(v))
+		}))
+	}
+	return data, fn, check, true, nil
+}
@@ -0,0 +1,97 @@
+package chdistr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBigInt struct {
+	Delta   *big.Int  `ch:"delta,int128"`
+	Debt    big.Int   `ch:"debt,int256"`
+	Nonce   *big.Int  `ch:"nonce,uint128"`
+	Balance *big.Int  `ch:"balance,uint256"`
+	History []big.Int `ch:"history,uint256"`
+}
+
+func TestBatchBigInt(t *testing.T) {
+	b, err := newBatch[testBigInt]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	maxUInt256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	row := testBigInt{
+		Delta:   big.NewInt(-2),
+		Debt:    *big.NewInt(5),
+		Nonce:   new(big.Int).Lsh(big.NewInt(1), 64),
+		Balance: maxUInt256,
+		History: []big.Int{*big.NewInt(1)},
+	}
+	nulls := row
+	nulls.Delta, nulls.Nonce, nulls.Balance = nil, nil, nil
+	for _, r := range []testBigInt{row, nulls} {
+		if !assert.Nil(t, b.check(r)) {
+			return
+		}
+		b.append(r)
+	}
+
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []proto.ColumnType{
+		"Nullable(Int128)",
+		"Int256",
+		"Nullable(UInt128)",
+		"Nullable(UInt256)",
+		"Array(UInt256)",
+	}, types)
+
+	delta, nonce, balance := b.input[0].Data.(*nullableColumn), b.input[2].Data.(*nullableColumn), b.input[3].Data.(*nullableColumn)
+	assert.Equal(t, proto.Int128{Low: 1<<64 - 2, High: 1<<64 - 1}, delta.Values.(*valueColumn).ColInput.(*proto.ColInt128).Row(0))
+	assert.Equal(t, proto.Int256FromInt(5), b.input[1].Data.(*proto.ColInt256).Row(0))
+	assert.Equal(t, proto.UInt128{High: 1}, nonce.Values.(*valueColumn).ColInput.(*proto.ColUInt128).Row(0))
+	assert.Equal(t, proto.UInt256{
+		Low:  proto.UInt128{Low: 1<<64 - 1, High: 1<<64 - 1},
+		High: proto.UInt128{Low: 1<<64 - 1, High: 1<<64 - 1},
+	}, balance.Values.(*valueColumn).ColInput.(*proto.ColUInt256).Row(0))
+	for _, col := range []*nullableColumn{delta, nonce, balance} {
+		assert.Equal(t, []uint8{0, 1}, []uint8(col.Nulls))
+	}
+
+	for _, invalid := range []func(r *testBigInt){
+		func(r *testBigInt) { r.Delta = new(big.Int).Lsh(big.NewInt(1), 127) },
+		func(r *testBigInt) { r.Nonce = big.NewInt(-1) },
+		func(r *testBigInt) { r.Balance = new(big.Int).Add(maxUInt256, big.NewInt(1)) },
+		func(r *testBigInt) { r.History = []big.Int{*big.NewInt(-1)} },
+	} {
+		r := row
+		invalid(&r)
+		assert.ErrorIs(t, b.check(r), ErrRowRejected)
+	}
+}
+
+func TestBatchInvalidBigInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		newBatch func() error
+		err      string
+	}{
+		{"conflicting options", newBatchErr[struct {
+			V *big.Int `ch:"v,int128,uint128"`
+		}], "conflicting tag options [int128 uint128]"},
+		{"string", newBatchErr[struct {
+			V string `ch:"v,int256"`
+		}], "Int256 is not supported for String"},
+		{"big.Rat", newBatchErr[struct {
+			V big.Rat `ch:"v,uint128"`
+		}], "UInt128 is not supported for String"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.newBatch(), tt.err)
+		})
+	}
+}
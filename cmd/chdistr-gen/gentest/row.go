@@ -0,0 +1,45 @@
+// Package gentest has row types with methods generated by chdistr-gen,
+// which columns are compared with columns of the same rows built by reflection.
+package gentest
+
+import (
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/google/uuid"
+)
+
+//go:generate go run ch-distr/cmd/chdistr-gen -type Row
+
+type (
+	UserID  uint64
+	Country string
+	Raw     []byte
+)
+
+type Row struct {
+	ID       uuid.UUID
+	UserID   UserID
+	Count    int
+	Size     uint
+	Ok       bool
+	Ratio    float32
+	Score    float64
+	Letter   rune
+	Flags    byte
+	Kind     string  `ch:"kind,lowcardinality"`
+	Country  Country `ch:"country,lowcardinality"`
+	Payload  []byte
+	Raw      Raw
+	Addr     proto.IPv4
+	Amount   proto.Decimal64
+	Created  time.Time
+	Day      time.Time `ch:"day,date"`
+	Day32    time.Time `ch:"day32,date32"`
+	Updated  time.Time `ch:"updated,datetime64=3"`
+	Duration time.Duration
+	Skipped  string `ch:"-"`
+}
+
+// ReflectedRow has fields of Row without generated methods.
+type ReflectedRow Row
@@ -0,0 +1,61 @@
+// Code generated by chdistr-gen. DO NOT EDIT.
+
+package gentest
+
+import (
+	"github.com/ClickHouse/ch-go/proto"
+
+	chdistr "ch-distr"
+)
+
+var _ chdistr.GeneratedRow[Row] = Row{}
+
+// ChdistrColumns implements chdistr.GeneratedRow.
+func (Row) ChdistrColumns() proto.Input {
+	return proto.Input{
+		{Name: "id", Data: &proto.ColUUID{}},
+		{Name: "user_id", Data: &proto.ColUInt64{}},
+		{Name: "count", Data: &proto.ColInt64{}},
+		{Name: "size", Data: &proto.ColUInt64{}},
+		{Name: "ok", Data: &proto.ColBool{}},
+		{Name: "ratio", Data: &proto.ColFloat32{}},
+		{Name: "score", Data: &proto.ColFloat64{}},
+		{Name: "letter", Data: &proto.ColInt32{}},
+		{Name: "flags", Data: &proto.ColUInt8{}},
+		{Name: "kind", Data: proto.NewLowCardinality[string](&proto.ColStr{})},
+		{Name: "country", Data: proto.NewLowCardinality[string](&proto.ColStr{})},
+		{Name: "payload", Data: &proto.ColStr{}},
+		{Name: "raw", Data: &proto.ColStr{}},
+		{Name: "addr", Data: &proto.ColIPv4{}},
+		{Name: "amount", Data: &proto.ColDecimal64{}},
+		{Name: "created", Data: &proto.ColDateTime{}},
+		{Name: "day", Data: &proto.ColDate{}},
+		{Name: "day32", Data: &proto.ColDate32{}},
+		{Name: "updated", Data: &proto.ColDateTime64{Precision: 3, PrecisionSet: true}},
+		{Name: "duration", Data: &proto.ColInt64{}},
+	}
+}
+
+// ChdistrAppend implements chdistr.GeneratedRow.
+func (Row) ChdistrAppend(row Row, input proto.Input) {
+	input[0].Data.(*proto.ColUUID).Append(row.ID)
+	input[1].Data.(*proto.ColUInt64).Append(uint64(row.UserID))
+	input[2].Data.(*proto.ColInt64).Append(int64(row.Count))
+	input[3].Data.(*proto.ColUInt64).Append(uint64(row.Size))
+	input[4].Data.(*proto.ColBool).Append(row.Ok)
+	input[5].Data.(*proto.ColFloat32).Append(row.Ratio)
+	input[6].Data.(*proto.ColFloat64).Append(row.Score)
+	input[7].Data.(*proto.ColInt32).Append(row.Letter)
+	input[8].Data.(*proto.ColUInt8).Append(row.Flags)
+	input[9].Data.(*proto.ColLowCardinality[string]).Append(row.Kind)
+	input[10].Data.(*proto.ColLowCardinality[string]).Append(string(row.Country))
+	input[11].Data.(*proto.ColStr).AppendBytes(row.Payload)
+	input[12].Data.(*proto.ColStr).AppendBytes([]byte(row.Raw))
+	input[13].Data.(*proto.ColIPv4).Append(row.Addr)
+	input[14].Data.(*proto.ColDecimal64).Append(row.Amount)
+	input[15].Data.(*proto.ColDateTime).Append(row.Created)
+	input[16].Data.(*proto.ColDate).Append(row.Day)
+	input[17].Data.(*proto.ColDate32).Append(row.Day32)
+	input[18].Data.(*proto.ColDateTime64).Append(row.Updated)
+	input[19].Data.(*proto.ColInt64).Append(int64(row.Duration))
+}
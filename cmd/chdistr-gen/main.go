@@ -0,0 +1,460 @@
+// Command chdistr-gen generates reflection-free batch methods of row types,
+// which implement chdistr.GeneratedRow.
+//
+// Usage in the package of row types:
+//
+//	//go:generate go run ch-distr/cmd/chdistr-gen -type Event,Click
+//
+// Fields are mapped to columns as by reflection with ch tag names and options,
+// but only primitive types (and named types of them), []byte, time.Time, time.Duration,
+// uuid.UUID and fixed size ch-go proto types are supported. Other fields are reported as errors.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	chdistr "ch-distr"
+)
+
+const importPath = "ch-distr"
+
+var namings = map[string]chdistr.NamingStrategy{
+	"snake": chdistr.SnakeCase,
+	"camel": chdistr.CamelCase,
+	"exact": chdistr.ExactCase,
+	"lower": chdistr.LowerCase,
+}
+
+func main() {
+	var (
+		types  = flag.String("type", "", "comma-separated list of row type names, required")
+		output = flag.String("output", "", "output file name, defaults to <type>_chdistr.go")
+		naming = flag.String("naming", "snake", "naming of fields without name in ch tag: snake, camel, exact or lower")
+	)
+	flag.Parse()
+
+	if err := run(".", *types, *output, *naming); err != nil {
+		fmt.Fprintln(os.Stderr, "chdistr-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, types, output, naming string) error {
+	if types == "" {
+		return errors.New("-type is required")
+	}
+
+	strategy, ok := namings[naming]
+	if !ok {
+		return fmt.Errorf("unknown naming %q", naming)
+	}
+
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		return err
+	}
+
+	names := strings.Split(types, ",")
+	src, err := generate(pkg, names, strategy)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = strings.ToLower(names[0]) + "_chdistr.go"
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
+
+// pkgInfo is the parsed package of row types.
+type pkgInfo struct {
+	name  string
+	types map[string]ast.Expr
+}
+
+func parsePackage(dir string) (*pkgInfo, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &pkgInfo{types: make(map[string]ast.Expr)}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || strings.HasSuffix(file, "_chdistr.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		pkg.name = f.Name.Name
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.TypeSpec)
+				pkg.types[spec.Name.Name] = spec.Type
+			}
+		}
+	}
+
+	if pkg.name == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return pkg, nil
+}
+
+// column is the generated column of field.
+type column struct {
+	name  string // ClickHouse column name
+	field string // Go field name
+	data  string // expression of new column
+	col   string // Go type of column
+	value string // format of appended value with the field selector
+}
+
+func generate(pkg *pkgInfo, types []string, naming chdistr.NamingStrategy) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by chdistr-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg.name)
+	fmt.Fprintf(&buf, "import (\n\t\"github.com/ClickHouse/ch-go/proto\"\n\n\tchdistr %q\n)\n", importPath)
+
+	for _, name := range types {
+		name = strings.TrimSpace(name)
+		st, ok := pkg.types[name].(*ast.StructType)
+		if !ok {
+			return nil, fmt.Errorf("type %s: struct type is not found", name)
+		}
+
+		cols, err := structColumns(pkg, st, naming)
+		if err != nil {
+			return nil, fmt.Errorf("type %s: %w", name, err)
+		}
+
+		fmt.Fprintf(&buf, "\nvar _ chdistr.GeneratedRow[%s] = %s{}\n", name, name)
+
+		fmt.Fprintf(&buf, "\n// ChdistrColumns implements chdistr.GeneratedRow.\n")
+		fmt.Fprintf(&buf, "func (%s) ChdistrColumns() proto.Input {\n\treturn proto.Input{\n", name)
+		for _, c := range cols {
+			fmt.Fprintf(&buf, "\t\t{Name: %q, Data: %s},\n", c.name, c.data)
+		}
+		fmt.Fprintf(&buf, "\t}\n}\n")
+
+		fmt.Fprintf(&buf, "\n// ChdistrAppend implements chdistr.GeneratedRow.\n")
+		fmt.Fprintf(&buf, "func (%s) ChdistrAppend(row %s, input proto.Input) {\n", name, name)
+		for i, c := range cols {
+			fmt.Fprintf(&buf, "\tinput[%d].Data.(%s).%s\n", i, c.col, fmt.Sprintf(c.value, "row."+c.field))
+		}
+		fmt.Fprintf(&buf, "}\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+func structColumns(pkg *pkgInfo, st *ast.StructType, naming chdistr.NamingStrategy) ([]column, error) {
+	var cols []column
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			s, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("embedded field %s is not supported", exprString(field.Type))
+		}
+
+		name, opts := parseTag(tag.Get("ch"))
+		for _, ident := range field.Names {
+			if !ident.IsExported() || name == "-" {
+				continue
+			}
+
+			c, err := fieldColumn(pkg, field.Type, opts)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", ident.Name, err)
+			}
+
+			c.field = ident.Name
+			if c.name = name; name == "" {
+				c.name = naming(ident.Name)
+			}
+			cols = append(cols, c)
+		}
+	}
+	return cols, nil
+}
+
+// basicColumns are columns of basic Go types.
+var basicColumns = map[string]column{
+	"bool":    {data: "&proto.ColBool{}", col: "*proto.ColBool"},
+	"int8":    {data: "&proto.ColInt8{}", col: "*proto.ColInt8"},
+	"int16":   {data: "&proto.ColInt16{}", col: "*proto.ColInt16"},
+	"int32":   {data: "&proto.ColInt32{}", col: "*proto.ColInt32"},
+	"rune":    {data: "&proto.ColInt32{}", col: "*proto.ColInt32"},
+	"int64":   {data: "&proto.ColInt64{}", col: "*proto.ColInt64"},
+	"int":     {data: "&proto.ColInt64{}", col: "*proto.ColInt64", value: "Append(int64(%s))"},
+	"uint8":   {data: "&proto.ColUInt8{}", col: "*proto.ColUInt8"},
+	"byte":    {data: "&proto.ColUInt8{}", col: "*proto.ColUInt8"},
+	"uint16":  {data: "&proto.ColUInt16{}", col: "*proto.ColUInt16"},
+	"uint32":  {data: "&proto.ColUInt32{}", col: "*proto.ColUInt32"},
+	"uint64":  {data: "&proto.ColUInt64{}", col: "*proto.ColUInt64"},
+	"uint":    {data: "&proto.ColUInt64{}", col: "*proto.ColUInt64", value: "Append(uint64(%s))"},
+	"float32": {data: "&proto.ColFloat32{}", col: "*proto.ColFloat32"},
+	"float64": {data: "&proto.ColFloat64{}", col: "*proto.ColFloat64"},
+	"string":  {data: "&proto.ColStr{}", col: "*proto.ColStr"},
+}
+
+// protoColumns are columns of fixed size ch-go proto types.
+var protoColumns = map[string]bool{
+	"IPv4": true, "IPv6": true, "Point": true,
+	"Int128": true, "Int256": true, "UInt128": true, "UInt256": true,
+	"Decimal32": true, "Decimal64": true, "Decimal128": true, "Decimal256": true,
+}
+
+// tagOptionTypes are tag options supported by chdistr-gen with types of fields each applies to.
+// Other options and options of other types fail generation as they fail newBatch.
+var tagOptionTypes = map[string][]string{
+	"lowcardinality": {"string"},
+	"date":           {"time.Time"},
+	"date32":         {"time.Time"},
+	"datetime":       {"time.Time"},
+	"datetime64":     {"time.Time"},
+}
+
+func fieldColumn(pkg *pkgInfo, typ ast.Expr, opts map[string]string) (column, error) {
+	name, conv := fieldType(pkg, typ)
+	if name == "" {
+		return column{}, fmt.Errorf("type %s is not supported", exprString(typ))
+	}
+
+	if err := checkTagOptions(name, opts); err != nil {
+		return column{}, err
+	}
+
+	if c, ok := basicColumns[name]; ok {
+		return basicColumn(name, c, conv, opts)
+	}
+
+	switch name {
+	case "[]byte":
+		if conv != "" {
+			return column{data: "&proto.ColStr{}", col: "*proto.ColStr", value: "AppendBytes([]byte(%s))"}, nil
+		}
+		return column{data: "&proto.ColStr{}", col: "*proto.ColStr", value: "AppendBytes(%s)"}, nil
+	case "time.Time":
+		return timeColumn(opts)
+	case "time.Duration":
+		return column{data: "&proto.ColInt64{}", col: "*proto.ColInt64", value: "Append(int64(%s))"}, nil
+	case "uuid.UUID":
+		return column{data: "&proto.ColUUID{}", col: "*proto.ColUUID", value: "Append(%s)"}, nil
+	}
+
+	col := strings.TrimPrefix(name, "proto.")
+	return column{
+		data:  "&proto.Col" + col + "{}",
+		col:   "*proto.Col" + col,
+		value: "Append(%s)",
+	}, nil
+}
+
+// fieldType returns name of supported type of field (basic type, []byte, time.Time, time.Duration,
+// uuid.UUID or proto type) and conversion of values of named types to it, name is empty for other types.
+func fieldType(pkg *pkgInfo, typ ast.Expr) (name, conv string) {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if _, ok := basicColumns[t.Name]; ok {
+			return t.Name, ""
+		}
+
+		// Named type of the package, e.g. `type UserID uint64`.
+		if underlying, ok := pkg.types[t.Name].(*ast.Ident); ok {
+			if _, ok := basicColumns[underlying.Name]; ok {
+				return underlying.Name, underlying.Name
+			}
+		}
+		if isByteSlice(pkg.types[t.Name]) {
+			return "[]byte", "[]byte"
+		}
+	case *ast.ArrayType:
+		if isByteSlice(t) {
+			return "[]byte", ""
+		}
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return "", ""
+		}
+
+		switch name := pkg.Name + "." + t.Sel.Name; name {
+		case "time.Time", "time.Duration", "uuid.UUID":
+			return name, ""
+		}
+
+		if pkg.Name == "proto" && protoColumns[t.Sel.Name] {
+			return "proto." + t.Sel.Name, ""
+		}
+	}
+	return "", ""
+}
+
+// checkTagOptions returns error for unknown options and options which don't apply to type.
+func checkTagOptions(typ string, opts map[string]string) error {
+	keys := make([]string, 0, len(opts))
+	for opt := range opts {
+		keys = append(keys, opt)
+	}
+	sort.Strings(keys)
+
+	for _, opt := range keys {
+		types, ok := tagOptionTypes[opt]
+		if !ok {
+			return fmt.Errorf("tag option %s is not supported", opt)
+		}
+
+		var applies bool
+		for _, t := range types {
+			applies = applies || t == typ
+		}
+		if !applies {
+			return fmt.Errorf("tag option %s is not supported for %s", opt, typ)
+		}
+	}
+	return nil
+}
+
+func basicColumn(name string, c column, conv string, opts map[string]string) (column, error) {
+	if _, ok := opts["lowcardinality"]; ok {
+		c.data = "proto.NewLowCardinality[string](&proto.ColStr{})"
+		c.col = "*proto.ColLowCardinality[string]"
+	}
+
+	switch {
+	case c.value != "":
+	case conv != "":
+		c.value = "Append(" + conv + "(%s))"
+	default:
+		c.value = "Append(%s)"
+	}
+	return c, nil
+}
+
+func timeColumn(opts map[string]string) (column, error) {
+	var set []string
+	for _, opt := range []string{"date", "date32", "datetime", "datetime64"} {
+		if has(opts, opt) {
+			set = append(set, opt)
+		}
+	}
+	if len(set) > 1 {
+		return column{}, fmt.Errorf("conflicting tag options %s", strings.Join(set, ", "))
+	}
+
+	c := column{value: "Append(%s)"}
+	switch {
+	case has(opts, "date"):
+		c.data, c.col = "&proto.ColDate{}", "*proto.ColDate"
+	case has(opts, "date32"):
+		c.data, c.col = "&proto.ColDate32{}", "*proto.ColDate32"
+	case has(opts, "datetime64"):
+		p, err := strconv.ParseUint(opts["datetime64"], 10, 8)
+		if err != nil || p > 9 {
+			return column{}, fmt.Errorf("invalid DateTime64 precision %q", opts["datetime64"])
+		}
+		c.data = fmt.Sprintf("&proto.ColDateTime64{Precision: %d, PrecisionSet: true}", p)
+		c.col = "*proto.ColDateTime64"
+	default:
+		c.data, c.col = "&proto.ColDateTime{}", "*proto.ColDateTime"
+	}
+	return c, nil
+}
+
+func has(opts map[string]string, opt string) bool {
+	_, ok := opts[opt]
+	return ok
+}
+
+func isByteSlice(typ ast.Expr) bool {
+	arr, ok := typ.(*ast.ArrayType)
+	if !ok || arr.Len != nil {
+		return false
+	}
+
+	elem, ok := arr.Elt.(*ast.Ident)
+	return ok && (elem.Name == "byte" || elem.Name == "uint8")
+}
+
+// parseTag splits ch tag into column name and options by the rules of chdistr: options are
+// separated by commas outside of parentheses, arguments follow "=" or are in parentheses.
+func parseTag(tag string) (string, map[string]string) {
+	parts := splitTopLevel(tag)
+	opts := make(map[string]string, len(parts)-1)
+	for _, opt := range parts[1:] {
+		if opt = strings.TrimSpace(opt); opt == "" {
+			continue
+		}
+
+		if i := strings.IndexAny(opt, "=("); i != -1 {
+			key, val := opt[:i], opt[i+1:]
+			if opt[i] == '(' {
+				val = strings.TrimSuffix(val, ")")
+			}
+			opts[strings.TrimSpace(key)] = strings.TrimSpace(val)
+			continue
+		}
+
+		opts[opt] = ""
+	}
+	return strings.TrimSpace(parts[0]), opts
+}
+
+// splitTopLevel splits s by commas which are not enclosed in parentheses.
+func splitTopLevel(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
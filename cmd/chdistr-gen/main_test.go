@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	chdistr "ch-distr"
+)
+
+const testSource = `package events
+
+import (
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/google/uuid"
+)
+
+type UserID uint64
+
+type Event struct {
+	ID      uuid.UUID
+	UserID  UserID
+	Count   int
+	Kind    string    ` + "`ch:\"event_kind,lowcardinality\"`" + `
+	Payload []byte
+	Addr    proto.IPv4
+	Created time.Time ` + "`ch:\",datetime64=3\"`" + `
+	Skipped string    ` + "`ch:\"-\"`" + `
+	private string
+}
+
+type Invalid struct {
+	Tags []string
+}
+`
+
+const testGenerated = `// Code generated by chdistr-gen. DO NOT EDIT.
+
+package events
+
+import (
+	"github.com/ClickHouse/ch-go/proto"
+
+	chdistr "ch-distr"
+)
+
+var _ chdistr.GeneratedRow[Event] = Event{}
+
+// ChdistrColumns implements chdistr.GeneratedRow.
+func (Event) ChdistrColumns() proto.Input {
+	return proto.Input{
+		{Name: "id", Data: &proto.ColUUID{}},
+		{Name: "user_id", Data: &proto.ColUInt64{}},
+		{Name: "count", Data: &proto.ColInt64{}},
+		{Name: "event_kind", Data: proto.NewLowCardinality[string](&proto.ColStr{})},
+		{Name: "payload", Data: &proto.ColStr{}},
+		{Name: "addr", Data: &proto.ColIPv4{}},
+		{Name: "created", Data: &proto.ColDateTime64{Precision: 3, PrecisionSet: true}},
+	}
+}
+
+// ChdistrAppend implements chdistr.GeneratedRow.
+func (Event) ChdistrAppend(row Event, input proto.Input) {
+	input[0].Data.(*proto.ColUUID).Append(row.ID)
+	input[1].Data.(*proto.ColUInt64).Append(uint64(row.UserID))
+	input[2].Data.(*proto.ColInt64).Append(int64(row.Count))
+	input[3].Data.(*proto.ColLowCardinality[string]).Append(row.Kind)
+	input[4].Data.(*proto.ColStr).AppendBytes(row.Payload)
+	input[5].Data.(*proto.ColIPv4).Append(row.Addr)
+	input[6].Data.(*proto.ColDateTime64).Append(row.Created)
+}
+`
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	if !assert.Nil(t, os.WriteFile(filepath.Join(dir, "events.go"), []byte(testSource), 0o644)) {
+		return
+	}
+
+	if !assert.Nil(t, run(dir, "Event", "", "snake")) {
+		return
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, "event_chdistr.go"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, testGenerated, string(src))
+
+	assert.ErrorContains(t, run(dir, "Invalid", "", "snake"), "field Tags: type []string is not supported")
+	assert.ErrorContains(t, run(dir, "Missing", "", "snake"), "struct type is not found")
+	assert.ErrorContains(t, run(dir, "Event", "", "kebab"), "unknown naming")
+	assert.ErrorContains(t, run(dir, "", "", "snake"), "-type is required")
+}
+
+func TestGenerateUpToDate(t *testing.T) {
+	pkg, err := parsePackage("gentest")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	src, err := generate(pkg, []string{"Row"}, chdistr.SnakeCase)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	generated, err := os.ReadFile(filepath.Join("gentest", "row_chdistr.go"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, string(generated), string(src), "run go generate in gentest")
+}
+
+func TestGenerateInvalidTagOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		err   string
+	}{
+		{"unknown option", "V string `ch:\"v,lowcardinalty\"`", "tag option lowcardinalty is not supported"},
+		{"unsupported option", "V float64 `ch:\"v,decimal(18,4)\"`", "tag option decimal is not supported"},
+		{"low cardinality of integer", "V int64 `ch:\"v,lowcardinality\"`", "tag option lowcardinality is not supported for int64"},
+		{"low cardinality of time", "V time.Time `ch:\"v,lowcardinality\"`", "tag option lowcardinality is not supported for time.Time"},
+		{"date of string", "V string `ch:\"v,date\"`", "tag option date is not supported for string"},
+		{"datetime64 of duration", "V time.Duration `ch:\"v,datetime64=3\"`", "tag option datetime64 is not supported for time.Duration"},
+		{"conflicting options", "V time.Time `ch:\"v,date,date32\"`", "conflicting tag options date, date32"},
+		{"invalid precision", "V time.Time `ch:\"v,datetime64=10\"`", `invalid DateTime64 precision "10"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := "package rows\n\nimport \"time\"\n\nvar _ time.Time\n\ntype Row struct {\n\t" + tt.field + "\n}\n"
+			if !assert.Nil(t, os.WriteFile(filepath.Join(dir, "rows.go"), []byte(src), 0o644)) {
+				return
+			}
+
+			assert.ErrorContains(t, run(dir, "Row", "", "snake"), "field V: "+tt.err)
+		})
+	}
+}
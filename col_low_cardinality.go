@@ -0,0 +1,168 @@
+package chdistr
+
+import (
+	"errors"
+	"math"
+
+	"github.com/ClickHouse/ch-go/proto"
+)
+
+// Low cardinality serialization constants, see proto.ColLowCardinality.
+const (
+	sharedDictionariesWithAdditionalKeys = 1
+
+	cardinalityHasAdditionalKeysBit = 1 << 9
+	cardinalityNeedUpdateDictionary = 1 << 10
+	cardinalityUpdateAll            = cardinalityHasAdditionalKeysBit | cardinalityNeedUpdateDictionary
+)
+
+var _ proto.ColumnOf[proto.Nullable[string]] = (*colLowCardinalityNullable[string])(nil)
+
+// colLowCardinalityNullable is LowCardinality(Nullable(T)) column.
+//
+// Unlike proto.ColLowCardinality with proto.ColNullable index, dictionary is encoded
+// as not nullable T and the first key of dictionary is reserved for NULL.
+type colLowCardinalityNullable[T comparable] struct {
+	Values []proto.Nullable[T]
+
+	index proto.ColumnOf[T]
+	key   proto.CardinalityKey
+
+	keys8  proto.ColUInt8
+	keys16 proto.ColUInt16
+	keys32 proto.ColUInt32
+	keys64 proto.ColUInt64
+
+	kv   map[T]int
+	keys []int
+}
+
+func newColLowCardinalityNullable[T comparable](index proto.ColumnOf[T]) *colLowCardinalityNullable[T] {
+	return &colLowCardinalityNullable[T]{
+		index: index,
+	}
+}
+
+func (c colLowCardinalityNullable[T]) Type() proto.ColumnType {
+	return proto.ColumnTypeLowCardinality.Sub(proto.ColumnTypeNullable.Sub(c.index.Type()))
+}
+
+func (c colLowCardinalityNullable[T]) Rows() int {
+	return len(c.Values)
+}
+
+func (c *colLowCardinalityNullable[T]) Append(v proto.Nullable[T]) {
+	c.Values = append(c.Values, v)
+}
+
+func (c colLowCardinalityNullable[T]) Row(i int) proto.Nullable[T] {
+	return c.Values[i]
+}
+
+func (c *colLowCardinalityNullable[T]) Reset() {
+	for k := range c.kv {
+		delete(c.kv, k)
+	}
+	c.keys = c.keys[:0]
+
+	c.keys8 = c.keys8[:0]
+	c.keys16 = c.keys16[:0]
+	c.keys32 = c.keys32[:0]
+	c.keys64 = c.keys64[:0]
+	c.Values = c.Values[:0]
+
+	c.index.Reset()
+}
+
+func (c *colLowCardinalityNullable[T]) DecodeColumn(r *proto.Reader, rows int) error {
+	return errors.New("decoding of LowCardinality(Nullable(T)) is not supported")
+}
+
+func (c colLowCardinalityNullable[T]) EncodeState(b *proto.Buffer) {
+	b.PutInt64(sharedDictionariesWithAdditionalKeys)
+	if s, ok := c.index.(proto.StateEncoder); ok {
+		s.EncodeState(b)
+	}
+}
+
+// Prepare fills dictionary and keys of column.
+func (c *colLowCardinalityNullable[T]) Prepare() error {
+	// Dictionary contains NULL placeholder.
+	if n := len(c.Values) + 1; n < math.MaxUint8 {
+		c.key = proto.KeyUInt8
+	} else if n < math.MaxUint16 {
+		c.key = proto.KeyUInt16
+	} else if uint32(n) < math.MaxUint32 {
+		c.key = proto.KeyUInt32
+	} else {
+		c.key = proto.KeyUInt64
+	}
+
+	if c.kv == nil {
+		c.kv = map[T]int{}
+	}
+
+	var null T
+	c.index.Reset()
+	c.index.Append(null)
+
+	c.keys = c.keys[:0]
+	for _, v := range c.Values {
+		if !v.Set {
+			c.keys = append(c.keys, 0)
+			continue
+		}
+
+		idx, ok := c.kv[v.Value]
+		if !ok {
+			idx = c.index.Rows()
+			c.index.Append(v.Value)
+			c.kv[v.Value] = idx
+		}
+		c.keys = append(c.keys, idx)
+	}
+
+	switch c.key {
+	case proto.KeyUInt8:
+		c.keys8 = fillKeys(c.keys, c.keys8)
+	case proto.KeyUInt16:
+		c.keys16 = fillKeys(c.keys, c.keys16)
+	case proto.KeyUInt32:
+		c.keys32 = fillKeys(c.keys, c.keys32)
+	case proto.KeyUInt64:
+		c.keys64 = fillKeys(c.keys, c.keys64)
+	}
+
+	return nil
+}
+
+func (c *colLowCardinalityNullable[T]) EncodeColumn(b *proto.Buffer) {
+	if c.Rows() == 0 {
+		return
+	}
+
+	b.PutInt64(cardinalityUpdateAll | int64(c.key))
+
+	b.PutInt64(int64(c.index.Rows()))
+	c.index.EncodeColumn(b)
+
+	b.PutInt64(int64(c.Rows()))
+	switch c.key {
+	case proto.KeyUInt8:
+		c.keys8.EncodeColumn(b)
+	case proto.KeyUInt16:
+		c.keys16.EncodeColumn(b)
+	case proto.KeyUInt32:
+		c.keys32.EncodeColumn(b)
+	case proto.KeyUInt64:
+		c.keys64.EncodeColumn(b)
+	}
+}
+
+func fillKeys[K ~uint8 | ~uint16 | ~uint32 | ~uint64](values []int, keys []K) []K {
+	keys = keys[:0]
+	for _, v := range values {
+		keys = append(keys, K(v))
+	}
+	return keys
+}
@@ -0,0 +1,188 @@
+package chdistr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+// typeTag is the column type declared by type tag option,
+// e.g. `ch:"amount,type=Nullable(Decimal(18,4))"`.
+type typeTag struct {
+	declared proto.ColumnType
+
+	// Fields of Nullable and Array elements, Map keys and values
+	// with type tag option of the element type.
+	elem, key, value reflect.StructField
+}
+
+// decimalPrecisions are max precisions of DecimalN(S) types.
+var decimalPrecisions = map[string]string{
+	"Decimal32":  "9",
+	"Decimal64":  "18",
+	"Decimal128": "38",
+	"Decimal256": "76",
+}
+
+// resolveTypeTag sets tag options of field which select column of the declared type,
+// e.g. lowcardinality for LowCardinality(String). Declared type is checked with the type
+// of created column, so the field type and the declared type mismatch is reported at newBatch.
+func resolveTypeTag(field reflect.StructField, opts tagOptions) (typeTag, error) {
+	tag := typeTag{
+		declared: proto.ColumnType(opts["type"]),
+		elem:     field,
+		key:      field,
+		value:    field,
+	}
+
+	t := tag.declared
+	for {
+		base, args := splitType(t)
+		switch base {
+		case proto.ColumnTypeLowCardinality:
+			if len(args) != 1 {
+				return tag, fmt.Errorf("invalid type %s", t)
+			}
+
+			opts["lowcardinality"] = ""
+			t = args[0]
+			continue
+		case proto.ColumnTypeNullable, proto.ColumnTypeArray:
+			if len(args) != 1 {
+				return tag, fmt.Errorf("invalid type %s", t)
+			}
+
+			tag.elem = withTypeTag(field, args[0], opts.Has("lowcardinality"))
+		case proto.ColumnTypeMap:
+			if len(args) != 2 {
+				return tag, fmt.Errorf("invalid type %s", t)
+			}
+
+			tag.key = withTypeTag(field, args[0], false)
+			tag.value = withTypeTag(field, args[1], false)
+		case "Decimal":
+			opts["decimal"] = strings.Join(typeArgs(args), ",")
+		case proto.ColumnTypeDecimal32, proto.ColumnTypeDecimal64, proto.ColumnTypeDecimal128, proto.ColumnTypeDecimal256:
+			if len(args) != 1 {
+				return tag, fmt.Errorf("invalid type %s", t)
+			}
+
+			opts["decimal"] = decimalPrecisions[string(base)] + "," + string(args[0])
+		case proto.ColumnTypeDateTime64:
+			if len(args) == 0 || len(args) > 2 {
+				return tag, fmt.Errorf("invalid type %s", t)
+			}
+
+			opts["datetime64"] = string(args[0])
+			if len(args) == 2 {
+				opts["tz"] = strings.Trim(string(args[1]), "'")
+			}
+		case proto.ColumnTypeDateTime:
+			opts["datetime"] = ""
+			if len(args) == 1 {
+				opts["tz"] = strings.Trim(string(args[0]), "'")
+			}
+		case proto.ColumnTypeFixedString:
+			if len(args) != 1 {
+				return tag, fmt.Errorf("invalid type %s", t)
+			}
+
+			opts["fixedstring"] = string(args[0])
+		case "JSON":
+			opts["json"] = "json"
+		case "Object":
+			opts["json"] = "object"
+		case proto.ColumnTypeDate, proto.ColumnTypeDate32, proto.ColumnTypeEnum8, proto.ColumnTypeEnum16,
+			proto.ColumnTypeIPv4, proto.ColumnTypeIPv6,
+			proto.ColumnTypeInt128, proto.ColumnTypeInt256, proto.ColumnTypeUInt128, proto.ColumnTypeUInt256,
+			"Ring", "LineString", "Polygon", "MultiLineString", "MultiPolygon":
+			opts[strings.ToLower(string(base))] = ""
+		default:
+			if scale := strings.TrimPrefix(string(base), string(proto.ColumnTypeInterval)); scale != string(base) && scale != "" {
+				opts["interval"] = strings.ToLower(scale)
+			}
+		}
+		return tag, nil
+	}
+}
+
+// withTypeTag returns field with type tag option of t, which is used to create columns of elements.
+// Other options of the field (e.g. stringer) are kept, lowcardinality is set by the declared type.
+func withTypeTag(field reflect.StructField, t proto.ColumnType, lowCardinality bool) reflect.StructField {
+	tag := ",type=" + string(t)
+	if lowCardinality {
+		tag += ",lowcardinality"
+	}
+
+	for _, opt := range splitTopLevel(field.Tag.Get("ch"))[1:] {
+		switch name, _, _ := strings.Cut(strings.TrimSpace(opt), "="); name {
+		case "type", "lowcardinality":
+		default:
+			tag += "," + opt
+		}
+	}
+
+	field.Tag = reflect.StructTag("ch:" + strconv.Quote(tag))
+	return field
+}
+
+// checkTypeTag reports whether column type t matches the declared type.
+func checkTypeTag(declared, t proto.ColumnType) error {
+	if normalizeType(declared) != normalizeType(t) {
+		return fmt.Errorf("type %s doesn't match column type %s of the field", declared, t)
+	}
+	return nil
+}
+
+// splitType splits type to the base type and arguments, e.g. Map(String, UInt64) to Map, [String, UInt64].
+func splitType(t proto.ColumnType) (proto.ColumnType, []proto.ColumnType) {
+	s := strings.TrimSpace(string(t))
+	start := strings.IndexByte(s, '(')
+	if start == -1 || !strings.HasSuffix(s, ")") {
+		return proto.ColumnType(s), nil
+	}
+
+	var args []proto.ColumnType
+	for _, arg := range splitTopLevel(s[start+1 : len(s)-1]) {
+		args = append(args, proto.ColumnType(strings.TrimSpace(arg)))
+	}
+	return proto.ColumnType(strings.TrimSpace(s[:start])), args
+}
+
+func typeArgs(args []proto.ColumnType) []string {
+	s := make([]string, len(args))
+	for i, arg := range args {
+		s[i] = string(arg)
+	}
+	return s
+}
+
+var decimalAlias = regexp.MustCompile(`Decimal(32|64|128|256)\((\d+)\)`)
+
+// normalizeType removes spaces outside of quotes, so Decimal(18,4) equals to Decimal(18, 4),
+// and replaces DecimalN(S) aliases with Decimal(P,S).
+func normalizeType(t proto.ColumnType) string {
+	var (
+		b      strings.Builder
+		quoted bool
+	)
+	for _, r := range string(t) {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case !quoted && unicode.IsSpace(r):
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return decimalAlias.ReplaceAllStringFunc(b.String(), func(s string) string {
+		m := decimalAlias.FindStringSubmatch(s)
+		return "Decimal(" + decimalPrecisions["Decimal"+m[1]] + "," + m[2] + ")"
+	})
+}
@@ -0,0 +1,94 @@
+package chdistr
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTypeTag struct {
+	Amount  *float64          `ch:"amount,type=Nullable(Decimal(18,4))"`
+	Tags    []string          `ch:"tags,type=Array(LowCardinality(String))"`
+	Country *string           `ch:"country,type=LowCardinality(Nullable(String))"`
+	Attrs   map[string]uint64 `ch:"attrs,type=Map(LowCardinality(String), UInt64)"`
+	Ts      time.Time         `ch:"ts,type=DateTime64(3, 'UTC')"`
+	Day     time.Time         `ch:"day,type=Date"`
+	Raw     proto.Decimal64   `ch:"raw,type=Decimal64(2)"`
+	IP      netip.Addr        `ch:"ip,type=IPv6"`
+	TTL     time.Duration     `ch:"ttl,type=IntervalMinute"`
+	Hash    string            `ch:"hash,type=FixedString(4)"`
+	Color   *testColor        `ch:"color,type=Nullable(String),stringer"`
+}
+
+func TestBatchTypeTag(t *testing.T) {
+	b, err := newBatch[testTypeTag]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	amount := 1.5
+	b.append(testTypeTag{
+		Amount: &amount,
+		Tags:   []string{"a"},
+		Attrs:  map[string]uint64{"b": 1},
+		Ts:     time.Now(),
+		Raw:    150,
+		IP:     netip.MustParseAddr("::1"),
+		TTL:    time.Hour,
+		Hash:   "abcd",
+		Color:  new(testColor),
+	})
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"Nullable(Decimal(18, 4))",
+		"Array(LowCardinality(String))",
+		"LowCardinality(Nullable(String))",
+		"Map(LowCardinality(String), UInt64)",
+		"DateTime64(3, 'UTC')",
+		"Date",
+		"Decimal(18, 2)",
+		"IPv6",
+		"IntervalMinute",
+		"FixedString(4)",
+		"Nullable(String)",
+	}, types)
+	assert.Equal(t, proto.Interval{Scale: proto.IntervalMinute, Value: 60}, b.input[8].Data.(*proto.ColInterval).Row(0))
+	assert.Equal(t, "red", b.input[10].Data.(*nullableColumn).Values.(*valueColumn).ColInput.(*proto.ColStr).Row(0))
+}
+
+func TestBatchTypeTagMismatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		newBatch func() error
+		err      string
+	}{
+		{"other type", newBatchErr[struct {
+			V string `ch:"v,type=UInt64"`
+		}], "type UInt64 doesn't match column type String of the field"},
+		{"nullable of non-pointer", newBatchErr[struct {
+			V string `ch:"v,type=Nullable(String)"`
+		}], "type Nullable(String) doesn't match column type String of the field"},
+		{"invalid arguments", newBatchErr[struct {
+			V map[string]string `ch:"v,type=Map(String)"`
+		}], "invalid type Map(String)"},
+		{"precision out of range", newBatchErr[struct {
+			V proto.Decimal64 `ch:"v,type=Decimal(38,2)"`
+		}], "precision 38 is out of range [10, 18] of Decimal64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.newBatch(), tt.err)
+		})
+	}
+}
+
+func TestNormalizeType(t *testing.T) {
+	assert.Equal(t, "Decimal(18,4)", normalizeType("Decimal( 18, 4 )"))
+	assert.Equal(t, "Enum8('a b'=1)", normalizeType("Enum8('a b' = 1)"))
+	assert.Equal(t, "Array(Decimal(38,2))", normalizeType("Array(Decimal128(2))"))
+}
@@ -0,0 +1,40 @@
+package chdistr
+
+import (
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+// ColumnarRow is implemented by row types which encode themselves into columns.
+// Batches of such rows are built without reflection, but still are routed,
+// flushed and retried by DistrInserter. It takes precedence over GeneratedRow.
+//
+// Methods with value receivers copy each row to heap on append,
+// use pointer receivers and pointer row type to avoid it.
+type ColumnarRow interface {
+	// Columns returns new empty columns of the row. It is called for each batch
+	// on zero value of the row type (or pointer to zero value).
+	Columns() proto.Input
+	// AppendTo appends the row to columns returned by Columns.
+	AppendTo(input proto.Input)
+}
+
+var columnarRowType = reflect.TypeOf((*ColumnarRow)(nil)).Elem()
+
+// columnarRow returns columns of T implementing ColumnarRow.
+func columnarRow[T any]() (proto.Input, bool) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() == reflect.Interface || !typ.Implements(columnarRowType) {
+		return nil, false
+	}
+
+	zero := reflect.Zero(typ)
+	if typ.Kind() == reflect.Ptr {
+		zero = reflect.New(typ.Elem())
+	}
+	return zero.Interface().(ColumnarRow).Columns(), true
+}
+
+func appendColumnarRow[T any](v T, input proto.Input) {
+	any(v).(ColumnarRow).AppendTo(input)
+}
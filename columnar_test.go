@@ -0,0 +1,83 @@
+package chdistr
+
+import (
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testColumnar struct {
+	ID   uint64
+	Tags []string
+}
+
+func (testColumnar) Columns() proto.Input {
+	return proto.Input{
+		{Name: "id", Data: &proto.ColUInt64{}},
+		{Name: "tags", Data: proto.NewArray[string](&proto.ColStr{})},
+	}
+}
+
+func (r *testColumnar) AppendTo(input proto.Input) {
+	input[0].Data.(*proto.ColUInt64).Append(r.ID)
+	input[1].Data.(*proto.ColArr[string]).Append(r.Tags)
+}
+
+type testColumnarValue struct {
+	ID uint64
+}
+
+func (testColumnarValue) Columns() proto.Input {
+	return proto.Input{{Name: "id", Data: &proto.ColUInt64{}}}
+}
+
+func (r testColumnarValue) AppendTo(input proto.Input) {
+	input[0].Data.(*proto.ColUInt64).Append(r.ID)
+}
+
+type testColumnarEmpty struct{}
+
+func (testColumnarEmpty) Columns() proto.Input { return nil }
+
+func (testColumnarEmpty) AppendTo(proto.Input) {}
+
+func TestBatchColumnarRow(t *testing.T) {
+	b, err := newBatch[*testColumnar]()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, b.appenders)
+
+	row := &testColumnar{ID: 1, Tags: []string{"a", "b"}}
+	assert.Nil(t, b.check(row))
+	b.append(row)
+	assert.Equal(t, uint64(1), b.input[0].Data.(*proto.ColUInt64).Row(0))
+	assert.Equal(t, []string{"a", "b"}, b.input[1].Data.(*proto.ColArr[string]).Row(0))
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		b.append(row)
+	}))
+
+	vb, err := newBatch[testColumnarValue]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	vb.append(testColumnarValue{ID: 2})
+	assert.Equal(t, uint64(2), vb.input[0].Data.(*proto.ColUInt64).Row(0))
+
+	// Methods of pointer receiver are not implemented by the struct type,
+	// so its batch is built by reflection.
+	rb, err := newBatch[testColumnar]()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Len(t, rb.appenders, 2)
+
+	_, err = newBatch[testColumnarEmpty]()
+	assert.ErrorIs(t, err, ErrNoColumns)
+
+	_, err = newBatch[ColumnarRow]()
+	assert.ErrorIs(t, err, ErrInvalidType)
+}
@@ -0,0 +1,237 @@
+package chdistr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+var (
+	bigIntType = reflect.TypeOf(big.Int{})
+	bigRatType = reflect.TypeOf(big.Rat{})
+)
+
+var errDecimalOverflow = errors.New("decimal overflow")
+
+// typedColumn overrides type of column, e.g. Decimal64 is sent as Decimal(18, 4).
+type typedColumn[T any] struct {
+	proto.ColumnOf[T]
+
+	typ proto.ColumnType
+}
+
+func (c *typedColumn[T]) Type() proto.ColumnType {
+	return c.typ
+}
+
+// decimalType returns precision and scale of decimal(P,S) or decimal(P) tag option.
+func decimalType(opts tagOptions) (precision, scale int, ok bool, err error) {
+	val, ok := opts.Get("decimal")
+	if !ok {
+		return 0, 0, false, nil
+	}
+
+	p, s, _ := strings.Cut(val, ",")
+	if precision, err = strconv.Atoi(strings.TrimSpace(p)); err != nil || precision < 1 || precision > 76 {
+		return 0, 0, false, fmt.Errorf("invalid Decimal precision %q", p)
+	}
+	if s != "" {
+		if scale, err = strconv.Atoi(strings.TrimSpace(s)); err != nil || scale < 0 || scale > precision {
+			return 0, 0, false, fmt.Errorf("invalid Decimal scale %q", s)
+		}
+	}
+	return precision, scale, true, nil
+}
+
+// ratGetter returns value as big.Rat.
+type ratGetter func(v any) (*big.Rat, error)
+
+// getRatGetter returns getter of float, string, big.Int and big.Rat values.
+// Pointers to big.Int and big.Rat are Nullable like other pointers.
+func getRatGetter(typ reflect.Type) (ratGetter, bool) {
+	switch {
+	case typ == bigIntType:
+		return func(v any) (*big.Rat, error) {
+			i := v.(big.Int)
+			return new(big.Rat).SetInt(&i), nil
+		}, true
+	case typ == bigRatType:
+		return func(v any) (*big.Rat, error) {
+			r := v.(big.Rat)
+			return &r, nil
+		}, true
+	}
+
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return func(v any) (*big.Rat, error) {
+			f := reflect.ValueOf(v).Float()
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, fmt.Errorf("invalid decimal %v", f)
+			}
+			return new(big.Rat).SetFloat64(f), nil
+		}, true
+	case reflect.String:
+		return func(v any) (*big.Rat, error) {
+			s := reflect.ValueOf(v).String()
+			r, ok := new(big.Rat).SetString(s)
+			if !ok {
+				return nil, fmt.Errorf("invalid decimal %q", s)
+			}
+			return r, nil
+		}, true
+	}
+
+	return nil, false
+}
+
+// getDecimalColAndAppender returns Decimal(P, S) column for the decimal tag option.
+// Values are rounded half away from zero to the scale, values which don't fit
+// in the precision are rejected by checker.
+func getDecimalColAndAppender(idx int, typ reflect.Type, opts tagOptions) (proto.ColInput, appender, checker, bool, error) {
+	precision, scale, ok, err := decimalType(opts)
+	if !ok || err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	typeOf := proto.ColumnType("Decimal").With(strconv.Itoa(precision), strconv.Itoa(scale))
+	if data, fn, ok, err := getRawDecimalColAndAppender(idx, typ, precision, typeOf); ok || err != nil {
+		return data, fn, nil, ok, err
+	}
+
+	get, ok := getRatGetter(typ)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	var (
+		factor = new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+		limit  = new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	)
+
+	// unscaled returns value multiplied by 10^scale.
+	unscaled := func(v any) (*big.Int, error) {
+		r, err := get(v)
+		if err != nil {
+			return nil, err
+		}
+
+		num := new(big.Int).Mul(r.Num(), factor)
+		q, m := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+		if m.Sign() != 0 && m.Abs(m).Lsh(m, 1).Cmp(r.Denom()) >= 0 {
+			q.Add(q, big.NewInt(int64(num.Sign())))
+		}
+
+		if new(big.Int).Abs(q).Cmp(limit) >= 0 {
+			return nil, fmt.Errorf("%w: %s doesn't fit in %s", errDecimalOverflow, r.FloatString(scale), typeOf)
+		}
+		return q, nil
+	}
+
+	var (
+		data  proto.ColInput
+		fn    appender
+		check checker
+	)
+	switch {
+	case precision <= 9:
+		data = &typedColumn[proto.Decimal32]{ColumnOf: &proto.ColDecimal32{}, typ: typeOf}
+		fn, check = newConvertingAppender(idx, convertBigInt(unscaled, func(v *big.Int) proto.Decimal32 {
+			return proto.Decimal32(v.Int64())
+		}))
+	case precision <= 18:
+		data = &typedColumn[proto.Decimal64]{ColumnOf: &proto.ColDecimal64{}, typ: typeOf}
+		fn, check = newConvertingAppender(idx, convertBigInt(unscaled, func(v *big.Int) proto.Decimal64 {
+			return proto.Decimal64(v.Int64())
+		}))
+	case precision <= 38:
+		data = &typedColumn[proto.Decimal128]{ColumnOf: &proto.ColDecimal128{}, typ: typeOf}
+		fn, check = newConvertingAppender(idx, convertBigInt(unscaled, func(v *big.Int) proto.Decimal128 {
+			return proto.Decimal128(bigToInt128(v))
+		}))
+	default:
+		data = &typedColumn[proto.Decimal256]{ColumnOf: &proto.ColDecimal256{}, typ: typeOf}
+		fn, check = newConvertingAppender(idx, convertBigInt(unscaled, func(v *big.Int) proto.Decimal256 {
+			return proto.Decimal256(bigToInt256(v))
+		}))
+	}
+	return data, fn, check, true, nil
+}
+
+// getRawDecimalColAndAppender returns Decimal(P, S) column for proto.Decimal32..256 values,
+// which are already multiplied by 10^S. Precision must be in range of the value size.
+func getRawDecimalColAndAppender(idx int, typ reflect.Type, precision int, typeOf proto.ColumnType) (proto.ColInput, appender, bool, error) {
+	if typ.PkgPath() != "github.com/ClickHouse/ch-go/proto" {
+		return nil, nil, false, nil
+	}
+
+	var (
+		data     proto.ColInput
+		fn       appender
+		min, max int
+	)
+	switch typ.Name() {
+	case "Decimal32":
+		data, fn, min, max = &typedColumn[proto.Decimal32]{ColumnOf: &proto.ColDecimal32{}, typ: typeOf}, newAppender[proto.Decimal32](idx), 1, 9
+	case "Decimal64":
+		data, fn, min, max = &typedColumn[proto.Decimal64]{ColumnOf: &proto.ColDecimal64{}, typ: typeOf}, newAppender[proto.Decimal64](idx), 10, 18
+	case "Decimal128":
+		data, fn, min, max = &typedColumn[proto.Decimal128]{ColumnOf: &proto.ColDecimal128{}, typ: typeOf}, newAppender[proto.Decimal128](idx), 19, 38
+	case "Decimal256":
+		data, fn, min, max = &typedColumn[proto.Decimal256]{ColumnOf: &proto.ColDecimal256{}, typ: typeOf}, newAppender[proto.Decimal256](idx), 39, 76
+	default:
+		return nil, nil, false, nil
+	}
+
+	if precision < min || precision > max {
+		return nil, nil, false, fmt.Errorf("precision %d is out of range [%d, %d] of %s", precision, min, max, typ.Name())
+	}
+	return data, fn, true, nil
+}
+
+// convertBigInt returns converter of values got by get as big.Int to T.
+func convertBigInt[T any](get func(v any) (*big.Int, error), to func(v *big.Int) T) converter[T] {
+	return func(v any) (T, error) {
+		i, err := get(v)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return to(i), nil
+	}
+}
+
+// twosComplement returns big-endian two's complement of v in size bytes.
+// v must fit in size bytes.
+func twosComplement(v *big.Int, size int) []byte {
+	if v.Sign() < 0 {
+		v = new(big.Int).Add(v, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+	}
+	return v.FillBytes(make([]byte, size))
+}
+
+func bytesToUInt128(b []byte) proto.UInt128 {
+	return proto.UInt128{
+		High: binary.BigEndian.Uint64(b[:8]),
+		Low:  binary.BigEndian.Uint64(b[8:16]),
+	}
+}
+
+func bigToInt128(v *big.Int) proto.Int128 {
+	return proto.Int128(bytesToUInt128(twosComplement(v, 16)))
+}
+
+func bigToInt256(v *big.Int) proto.Int256 {
+	b := twosComplement(v, 32)
+	return proto.Int256{
+		High: bytesToUInt128(b[:16]),
+		Low:  bytesToUInt128(b[16:]),
+	}
+}
@@ -0,0 +1,91 @@
+package chdistr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDecimal struct {
+	Price  float64  `ch:"price,decimal(9,2)"`
+	Amount string   `ch:"amount,decimal(18,4)"`
+	Wei    *big.Int `ch:"wei,decimal(38)"`
+	Ratio  *big.Rat `ch:"ratio,decimal(76,10)"`
+	Opt    *float64 `ch:"opt,decimal(18,4)"`
+}
+
+func TestBatchDecimal(t *testing.T) {
+	b, err := newBatch[testDecimal]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	row := testDecimal{
+		Price:  -12.345,
+		Amount: "1234.56789",
+		Wei:    big.NewInt(-1),
+		Ratio:  big.NewRat(1, 3),
+	}
+	nulls := row
+	nulls.Wei, nulls.Ratio = nil, nil
+	for _, r := range []testDecimal{row, nulls} {
+		if !assert.Nil(t, b.check(r)) {
+			return
+		}
+		b.append(r)
+	}
+
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []proto.ColumnType{
+		"Decimal(9, 2)",
+		"Decimal(18, 4)",
+		"Nullable(Decimal(38, 0))",
+		"Nullable(Decimal(76, 10))",
+		"Nullable(Decimal(18, 4))",
+	}, types)
+
+	wei, ratio := b.input[2].Data.(*nullableColumn), b.input[3].Data.(*nullableColumn)
+	assert.Equal(t, proto.Decimal32(-1235), b.input[0].Data.(proto.ColumnOf[proto.Decimal32]).Row(0))
+	assert.Equal(t, proto.Decimal64(12345679), b.input[1].Data.(proto.ColumnOf[proto.Decimal64]).Row(0))
+	assert.Equal(t, proto.Decimal128{Low: 1<<64 - 1, High: 1<<64 - 1}, wei.Values.(*valueColumn).ColInput.(proto.ColumnOf[proto.Decimal128]).Row(0))
+	assert.Equal(t, proto.Decimal256{Low: proto.UInt128{Low: 3333333333}}, ratio.Values.(*valueColumn).ColInput.(proto.ColumnOf[proto.Decimal256]).Row(0))
+	assert.Equal(t, []uint8{0, 1}, []uint8(wei.Nulls))
+	assert.Equal(t, []uint8{0, 1}, []uint8(ratio.Nulls))
+
+	for _, invalid := range []func(r *testDecimal){
+		func(r *testDecimal) { r.Price = 1e7 },
+		func(r *testDecimal) { r.Amount = "1e14" },
+		func(r *testDecimal) { r.Amount = "abc" },
+		func(r *testDecimal) { r.Wei = new(big.Int).Exp(big.NewInt(10), big.NewInt(38), nil) },
+	} {
+		r := row
+		invalid(&r)
+		assert.ErrorIs(t, b.check(r), ErrRowRejected)
+	}
+}
+
+func TestBatchInvalidDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		newBatch func() error
+		err      string
+	}{
+		{"precision out of range", newBatchErr[struct {
+			V float64 `ch:"v,decimal(77,2)"`
+		}], `invalid Decimal precision "77"`},
+		{"scale greater than precision", newBatchErr[struct {
+			V float64 `ch:"v,decimal(2,3)"`
+		}], `invalid Decimal scale "3"`},
+		{"integer", newBatchErr[struct {
+			V int64 `ch:"v,decimal(18,4)"`
+		}], "Decimal is not supported for Int64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.newBatch(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package chdistr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// durationUnits are units of Int64 column selected by tag option, e.g. `ch:"latency,ms"`.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// intervalUnits are scales of Interval column selected by interval tag option,
+// e.g. `ch:"ttl,interval=second"`. Months, quarters and years have no fixed duration.
+var intervalUnits = map[string]struct {
+	scale proto.IntervalScale
+	unit  time.Duration
+}{
+	"second": {proto.IntervalSecond, time.Second},
+	"minute": {proto.IntervalMinute, time.Minute},
+	"hour":   {proto.IntervalHour, time.Hour},
+	"day":    {proto.IntervalDay, 24 * time.Hour},
+	"week":   {proto.IntervalWeek, 7 * 24 * time.Hour},
+}
+
+// newDurationColumn creates column for time.Duration: Int64 of nanoseconds (by default)
+// or of the unit of tag option, or Interval of interval=scale tag option.
+// Durations are truncated to the unit.
+func newDurationColumn(idx int, opts tagOptions) (proto.ColInput, appender, error) {
+	var set []string
+	for _, opt := range []string{"ns", "us", "ms", "s", "interval"} {
+		if opts.Has(opt) {
+			set = append(set, opt)
+		}
+	}
+	if len(set) > 1 {
+		return nil, nil, fmt.Errorf("conflicting tag options %s", strings.Join(set, ", "))
+	}
+
+	if scale, ok := opts.Get("interval"); ok {
+		interval, ok := intervalUnits[strings.ToLower(scale)]
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported Interval scale %q", scale)
+		}
+
+		return &proto.ColInterval{Scale: interval.scale}, func(v any, input proto.Input) {
+			input[idx].Data.(*proto.ColInterval).Append(proto.Interval{
+				Scale: interval.scale,
+				Value: int64(v.(time.Duration) / interval.unit),
+			})
+		}, nil
+	}
+
+	unit := time.Nanosecond
+	if len(set) == 1 {
+		unit = durationUnits[set[0]]
+	}
+
+	return &proto.ColInt64{}, func(v any, input proto.Input) {
+		input[idx].Data.(*proto.ColInt64).Append(int64(v.(time.Duration) / unit))
+	}, nil
+}
@@ -0,0 +1,73 @@
+package chdistr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDuration struct {
+	Elapsed time.Duration
+	Latency time.Duration  `ch:"latency,ms"`
+	Uptime  time.Duration  `ch:"uptime,s"`
+	TTL     time.Duration  `ch:"ttl,interval=second"`
+	Period  time.Duration  `ch:"period,interval=day"`
+	Timeout *time.Duration `ch:"timeout,us"`
+}
+
+func TestBatchDuration(t *testing.T) {
+	b, err := newBatch[testDuration]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b.append(testDuration{
+		Elapsed: 1500 * time.Nanosecond,
+		Latency: 1500 * time.Microsecond,
+		Uptime:  time.Hour,
+		TTL:     time.Minute,
+		Period:  48 * time.Hour,
+	})
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"Int64",
+		"Int64",
+		"Int64",
+		"IntervalSecond",
+		"IntervalDay",
+		"Nullable(Int64)",
+	}, types)
+
+	assert.Equal(t, int64(1500), b.input[0].Data.(*proto.ColInt64).Row(0))
+	assert.Equal(t, int64(1), b.input[1].Data.(*proto.ColInt64).Row(0))
+	assert.Equal(t, int64(3600), b.input[2].Data.(*proto.ColInt64).Row(0))
+	assert.Equal(t, proto.Interval{Scale: proto.IntervalSecond, Value: 60}, b.input[3].Data.(*proto.ColInterval).Row(0))
+	assert.Equal(t, proto.Interval{Scale: proto.IntervalDay, Value: 2}, b.input[4].Data.(*proto.ColInterval).Row(0))
+}
+
+func TestBatchInvalidDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		newBatch func() error
+		err      string
+	}{
+		{"conflicting options", newBatchErr[struct {
+			D time.Duration `ch:"d,ms,interval=second"`
+		}], "conflicting tag options ms, interval"},
+		{"interval scale without fixed duration", newBatchErr[struct {
+			D time.Duration `ch:"d,interval=month"`
+		}], `unsupported Interval scale "month"`},
+		{"unit of integer", newBatchErr[struct {
+			D int64 `ch:"d,s"`
+		}], "tag option s is not supported for Int64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.newBatch(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package chdistr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+var errUnknownEnumValue = errors.New("unknown enum value")
+
+type enumValue interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~int |
+		~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uint |
+		~string
+}
+
+type enumElement struct {
+	name string
+	code int64
+}
+
+// enumInfo is the registered enum of Go type.
+type enumInfo struct {
+	names    map[any]string
+	elements []enumElement // sorted by code
+}
+
+var enums sync.Map // map[reflect.Type]*enumInfo
+
+// RegisterEnum registers names of ClickHouse enum elements for values of type E.
+// Fields of type E with enum8 or enum16 tag option (e.g. `ch:"status,enum8"`)
+// are mapped to Enum8/Enum16 columns, rows with values which are not registered
+// are rejected by Push.
+//
+// Element code is the value itself for integer types.
+// For string types codes are assigned in ascending order of names, but
+// on insert the actual codes are taken from the table definition.
+// Names can't contain quotes, commas and equal signs, since enum types are parsed by ch-go without escaping.
+func RegisterEnum[E enumValue](names map[E]string) error {
+	typ := reflect.TypeOf(*new(E))
+
+	info := &enumInfo{
+		names:    make(map[any]string, len(names)),
+		elements: make([]enumElement, 0, len(names)),
+	}
+
+	seen := make(map[string]struct{}, len(names))
+	for v, name := range names {
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("enum %s: duplicate name %q", typ, name)
+		}
+		seen[name] = struct{}{}
+
+		if strings.ContainsAny(name, "',=") {
+			return fmt.Errorf("enum %s: name %q contains one of ', =", typ, name)
+		}
+
+		info.names[v] = name
+
+		var code int64
+		switch rv := reflect.ValueOf(v); rv.Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+			code = rv.Int()
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+			if code = int64(rv.Uint()); code < 0 {
+				return fmt.Errorf("enum %s: code of %q is out of range", typ, name)
+			}
+		}
+		info.elements = append(info.elements, enumElement{name: name, code: code})
+	}
+
+	if typ.Kind() == reflect.String {
+		sort.Slice(info.elements, func(i, j int) bool {
+			return info.elements[i].name < info.elements[j].name
+		})
+		for i := range info.elements {
+			info.elements[i].code = int64(i + 1)
+		}
+	} else {
+		sort.Slice(info.elements, func(i, j int) bool {
+			return info.elements[i].code < info.elements[j].code
+		})
+	}
+
+	enums.Store(typ, info)
+	return nil
+}
+
+func lookupEnum(typ reflect.Type) (*enumInfo, bool) {
+	info, ok := enums.Load(typ)
+	if !ok {
+		return nil, false
+	}
+	return info.(*enumInfo), true
+}
+
+// columnType returns Enum8(...) or Enum16(...) type of enum.
+func (e *enumInfo) columnType(base proto.ColumnType) (proto.ColumnType, error) {
+	min, max := int64(-1<<7), int64(1<<7-1)
+	if base == proto.ColumnTypeEnum16 {
+		min, max = -1<<15, 1<<15-1
+	}
+
+	defs := make([]string, 0, len(e.elements))
+	for _, elem := range e.elements {
+		if elem.code < min || elem.code > max {
+			return "", fmt.Errorf("code %d of %q is out of %s range", elem.code, elem.name, base)
+		}
+
+		name := strings.ReplaceAll(elem.name, "'", `\'`)
+		defs = append(defs, "'"+name+"' = "+strconv.FormatInt(elem.code, 10))
+	}
+
+	return proto.ColumnType(base.String() + "(" + strings.Join(defs, ", ") + ")"), nil
+}
+
+func newEnumColumn(info *enumInfo, base proto.ColumnType) (*proto.ColEnum, error) {
+	if len(info.elements) == 0 {
+		return nil, errors.New("enum has no elements")
+	}
+
+	t, err := info.columnType(base)
+	if err != nil {
+		return nil, err
+	}
+
+	col := &proto.ColEnum{}
+	if err := col.Infer(t); err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+func newEnumAppender(idx int, info *enumInfo) appender {
+	return func(v any, input proto.Input) {
+		name, ok := info.names[v]
+		if !ok {
+			// Zero value of NULL, other values which are not registered are rejected by checker.
+			name = info.elements[0].name
+		}
+		input[idx].Data.(*proto.ColEnum).Append(name)
+	}
+}
+
+func newEnumChecker(info *enumInfo) checker {
+	return func(v any) error {
+		if _, ok := info.names[v]; !ok {
+			return fmt.Errorf("%w %v", errUnknownEnumValue, v)
+		}
+		return nil
+	}
+}
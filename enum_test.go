@@ -0,0 +1,138 @@
+package chdistr
+
+import (
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStatus string
+
+const (
+	testStatusActive  testStatus = "active"
+	testStatusBlocked testStatus = "blocked"
+)
+
+type testLevel int8
+
+const (
+	testLevelDebug testLevel = iota - 1
+	testLevelInfo
+	testLevelError
+)
+
+type testEnums struct {
+	Status  testStatus   `ch:"status,enum8"`
+	Level   testLevel    `ch:"level,enum16"`
+	History []testStatus `ch:"history,enum8"`
+}
+
+func registerTestEnums(t testing.TB) {
+	assert.Nil(t, RegisterEnum(map[testStatus]string{
+		testStatusActive:  string(testStatusActive),
+		testStatusBlocked: string(testStatusBlocked),
+	}))
+	assert.Nil(t, RegisterEnum(map[testLevel]string{
+		testLevelDebug: "debug",
+		testLevelInfo:  "info",
+		testLevelError: "error",
+	}))
+}
+
+func TestRegisterEnumDuplicateName(t *testing.T) {
+	err := RegisterEnum(map[testLevel]string{
+		testLevelDebug: "debug",
+		testLevelInfo:  "debug",
+	})
+	assert.NotNil(t, err)
+}
+
+func TestRegisterEnumInvalidName(t *testing.T) {
+	for _, name := range []string{"it's", "a,b", "a=1"} {
+		err := RegisterEnum(map[testLevel]string{
+			testLevelDebug: name,
+		})
+		assert.ErrorContains(t, err, "contains one of ', =")
+	}
+}
+
+func TestBatchEnum(t *testing.T) {
+	registerTestEnums(t)
+
+	b, err := newBatch[testEnums]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	types := columnTypes(t, b.input, 0)
+	assert.Equal(t, []proto.ColumnType{
+		"Enum8('active' = 1, 'blocked' = 2)",
+		"Enum16('debug' = -1, 'info' = 0, 'error' = 1)",
+		"Array(Enum8('active' = 1, 'blocked' = 2))",
+	}, types)
+
+	row := testEnums{
+		Status:  testStatusBlocked,
+		Level:   testLevelError,
+		History: []testStatus{testStatusActive, testStatusBlocked},
+	}
+	assert.Nil(t, b.check(row))
+	b.append(row)
+
+	assert.Equal(t, []string{"blocked"}, b.input[0].Data.(*proto.ColEnum).Values)
+	assert.Equal(t, []string{"error"}, b.input[1].Data.(*proto.ColEnum).Values)
+
+	assert.ErrorIs(t, b.check(testEnums{Status: "unknown", Level: testLevelInfo}), ErrRowRejected)
+	assert.ErrorIs(t, b.check(testEnums{Status: testStatusActive, Level: 10}), ErrRowRejected)
+	assert.ErrorIs(t, b.check(testEnums{
+		Status:  testStatusActive,
+		History: []testStatus{"unknown"},
+	}), ErrRowRejected)
+}
+
+func TestBatchEnumNotRegistered(t *testing.T) {
+	type unregistered string
+
+	_, err := newBatch[struct {
+		U unregistered `ch:"u,enum8"`
+	}]()
+	assert.NotNil(t, err)
+}
+
+type testNullableEnum struct {
+	Status *testStatus `ch:"status,enum8"`
+}
+
+func TestBatchNullableEnum(t *testing.T) {
+	registerTestEnums(t)
+
+	b, err := newBatch[testNullableEnum]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	status := testStatusBlocked
+	b.append(testNullableEnum{Status: &status})
+	b.append(testNullableEnum{})
+
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []proto.ColumnType{"Nullable(Enum8('active' = 1, 'blocked' = 2))"}, types)
+
+	// Codes of enum are inferred from the table.
+	tableType := proto.ColumnType("Nullable(Enum8('deleted' = 1, 'active' = 2, 'blocked' = 3))")
+	assert.Nil(t, b.input[0].Data.(proto.Inferable).Infer(tableType))
+
+	values := new(proto.ColEnum)
+	values.Values = []string{"blocked", "active"} // NULL is appended as the first element
+	assert.Nil(t, values.Infer(tableType.Elem()))
+	assert.Nil(t, values.Prepare())
+	expected := proto.NewColNullable[string](values)
+	expected.Nulls = []uint8{0, 1}
+
+	var buf, expectedBuf proto.Buffer
+	block := proto.Block{Columns: 1, Rows: 2}
+	assert.Nil(t, block.EncodeRawBlock(&buf, 54451, b.input))
+	assert.Nil(t, block.EncodeRawBlock(&expectedBuf, 54451, proto.Input{{Name: "status", Data: expected}}))
+	assert.Equal(t, expectedBuf.Buf, buf.Buf)
+}
@@ -0,0 +1,12 @@
+package chdistr
+
+import "github.com/ClickHouse/ch-go/proto"
+
+// BatchColumns returns columns of batch for rows of type T to external tests.
+func BatchColumns[T any]() (proto.Input, error) {
+	b, err := newBatch[T]()
+	if err != nil {
+		return nil, err
+	}
+	return b.input, nil
+}
@@ -0,0 +1,23 @@
+package chdistr
+
+import "github.com/ClickHouse/ch-go/proto"
+
+// GeneratedRow is implemented by row types with methods generated by chdistr-gen
+// (see cmd/chdistr-gen). Batches of such rows are built without reflection:
+//
+//	//go:generate go run ch-distr/cmd/chdistr-gen -type Event
+//
+// Methods have value receivers and ignore them, so T must be the struct type, not a pointer.
+type GeneratedRow[T any] interface {
+	// ChdistrColumns returns new empty columns of the row.
+	ChdistrColumns() proto.Input
+	// ChdistrAppend appends row to columns returned by ChdistrColumns.
+	ChdistrAppend(row T, input proto.Input)
+}
+
+// generatedRow returns GeneratedRow implemented by T.
+func generatedRow[T any]() (GeneratedRow[T], bool) {
+	var zero T
+	gen, ok := any(zero).(GeneratedRow[T])
+	return gen, ok
+}
@@ -0,0 +1,33 @@
+package chdistr_test
+
+import (
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+
+	chdistr "ch-distr"
+	"ch-distr/cmd/chdistr-gen/gentest"
+)
+
+func TestGeneratedSameAsReflected(t *testing.T) {
+	generated, err := chdistr.BatchColumns[gentest.Row]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	reflected, err := chdistr.BatchColumns[gentest.ReflectedRow]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	columns := func(input proto.Input) []string {
+		cols := make([]string, len(input))
+		for i, col := range input {
+			cols[i] = col.Name + " " + string(col.Data.Type())
+		}
+		return cols
+	}
+	assert.Equal(t, columns(reflected), columns(generated))
+	assert.Len(t, generated, 20)
+}
@@ -0,0 +1,58 @@
+package chdistr
+
+import (
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// testGenerated is written as chdistr-gen would generate it.
+type testGenerated struct {
+	ID   uint64
+	Name string
+}
+
+func (testGenerated) ChdistrColumns() proto.Input {
+	return proto.Input{
+		{Name: "id", Data: &proto.ColUInt64{}},
+		{Name: "name", Data: &proto.ColStr{}},
+	}
+}
+
+func (testGenerated) ChdistrAppend(row testGenerated, input proto.Input) {
+	input[0].Data.(*proto.ColUInt64).Append(row.ID)
+	input[1].Data.(*proto.ColStr).Append(row.Name)
+}
+
+func TestBatchGenerated(t *testing.T) {
+	b, err := newBatch[testGenerated]()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Nil(t, b.appenders)
+
+	row := testGenerated{ID: 1, Name: "foo"}
+	assert.Nil(t, b.check(row))
+	b.append(row)
+	assert.Equal(t, uint64(1), b.input[0].Data.(*proto.ColUInt64).Row(0))
+	assert.Equal(t, "foo", b.input[1].Data.(*proto.ColStr).Row(0))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		b.append(row)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkBatchAppendGenerated(b *testing.B) {
+	bt, err := newBatch[testGenerated]()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bt.append(testGenerated{ID: uint64(i), Name: "name"})
+	}
+}
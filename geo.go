@@ -0,0 +1,109 @@
+package chdistr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+var pointType = reflect.TypeOf(proto.Point{})
+
+// geoTypes are geo columns by nesting depth of point slices. Columns of the same depth
+// are selected by tag option of the type name in lower case, the first one is default.
+var geoTypes = [...][]proto.ColumnType{
+	1: {"Ring", "LineString"},
+	2: {"Polygon", "MultiLineString"},
+	3: {"MultiPolygon"},
+}
+
+var geoGoTypes = [...]reflect.Type{
+	1: reflect.TypeOf([]proto.Point(nil)),
+	2: reflect.TypeOf([][]proto.Point(nil)),
+	3: reflect.TypeOf([][][]proto.Point(nil)),
+}
+
+// pointsDepth returns nesting depth of slices of proto.Point, e.g. 2 for [][]proto.Point.
+func pointsDepth(typ reflect.Type) int {
+	var depth int
+	for ; typ.Kind() == reflect.Slice; typ = typ.Elem() {
+		depth++
+	}
+
+	if typ != pointType || depth >= len(geoTypes) {
+		return 0
+	}
+	return depth
+}
+
+// geoType returns geo column type of depth selected by tag options.
+func geoType(depth int, opts tagOptions) (proto.ColumnType, bool, error) {
+	var set []proto.ColumnType
+	for _, types := range geoTypes {
+		for _, t := range types {
+			if opts.Has(strings.ToLower(string(t))) {
+				set = append(set, t)
+			}
+		}
+	}
+
+	switch {
+	case len(set) > 1:
+		return "", false, fmt.Errorf("conflicting tag options %s", strings.ToLower(fmt.Sprint(set)))
+	case len(set) == 0:
+		return geoTypes[depth][0], true, nil
+	}
+
+	for _, t := range geoTypes[depth] {
+		if t == set[0] {
+			return t, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// getGeoColAndAppender returns Ring, Polygon or MultiPolygon column (or LineString and MultiLineString
+// selected by tag option) for slices of proto.Point. With tag option of lower depth,
+// e.g. `ch:"rings,ring"` for [][]proto.Point, the field is mapped to Array of geo type.
+func getGeoColAndAppender(idx int, typ reflect.Type, opts tagOptions) (proto.ColInput, appender, checker, bool, error) {
+	depth := pointsDepth(typ)
+	if depth == 0 {
+		return nil, nil, nil, false, nil
+	}
+
+	colType, ok, err := geoType(depth, opts)
+	if !ok || err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	// Named slices of named slices, e.g. `type Polygon []Ring`, can't be converted.
+	if !typ.ConvertibleTo(geoGoTypes[depth]) {
+		return nil, nil, nil, false, nil
+	}
+
+	ring := proto.NewArray[proto.Point](&proto.ColPoint{})
+	switch depth {
+	case 1:
+		return &typedColumn[[]proto.Point]{ColumnOf: ring, typ: colType}, newGeoAppender[[]proto.Point](idx, typ), nil, true, nil
+	case 2:
+		polygon := proto.NewArray[[]proto.Point](ring)
+		return &typedColumn[[][]proto.Point]{ColumnOf: polygon, typ: colType}, newGeoAppender[[][]proto.Point](idx, typ), nil, true, nil
+	default:
+		multi := proto.NewArray[[][]proto.Point](proto.NewArray[[]proto.Point](ring))
+		return &typedColumn[[][][]proto.Point]{ColumnOf: multi, typ: colType}, newGeoAppender[[][][]proto.Point](idx, typ), nil, true, nil
+	}
+}
+
+// newGeoAppender appends slices of points, values of named types (e.g. `type Ring []proto.Point`)
+// are converted to T.
+func newGeoAppender[T [][][]proto.Point | [][]proto.Point | []proto.Point](idx int, typ reflect.Type) appender {
+	target := reflect.TypeOf(T(nil))
+	if typ == target {
+		return newAppender[T](idx)
+	}
+
+	return func(v any, input proto.Input) {
+		input[idx].Data.(proto.ColumnOf[T]).Append(reflect.ValueOf(v).Convert(target).Interface().(T))
+	}
+}
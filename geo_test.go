@@ -0,0 +1,76 @@
+package chdistr
+
+import (
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRing []proto.Point
+
+type testGeo struct {
+	Ring     []proto.Point
+	Named    testRing
+	Line     []proto.Point `ch:"line,linestring"`
+	Polygon  [][]proto.Point
+	Rings    [][]proto.Point `ch:"rings,ring"`
+	Lines    [][]proto.Point `ch:"lines,multilinestring"`
+	Polygons [][][]proto.Point
+}
+
+func TestBatchGeo(t *testing.T) {
+	b, err := newBatch[testGeo]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	ring := []proto.Point{{X: 1, Y: 2}, {X: 3, Y: 4}}
+	b.append(testGeo{
+		Ring:     ring,
+		Named:    ring,
+		Line:     ring,
+		Polygon:  [][]proto.Point{ring, ring},
+		Rings:    [][]proto.Point{ring},
+		Lines:    [][]proto.Point{ring},
+		Polygons: [][][]proto.Point{{ring}, {ring, ring}},
+	})
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"Ring",
+		"Ring",
+		"LineString",
+		"Polygon",
+		"Array(Ring)",
+		"MultiLineString",
+		"MultiPolygon",
+	}, types)
+
+	assert.Equal(t, ring, b.input[1].Data.(proto.ColumnOf[[]proto.Point]).Row(0))
+	assert.Equal(t, [][][]proto.Point{{ring}, {ring, ring}}, b.input[6].Data.(proto.ColumnOf[[][][]proto.Point]).Row(0))
+}
+
+func TestBatchInvalidGeo(t *testing.T) {
+	tests := []struct {
+		name     string
+		newBatch func() error
+		err      string
+	}{
+		{"type of other depth", newBatchErr[struct {
+			V []proto.Point `ch:"v,polygon"`
+		}], "Polygon is not supported for Point"},
+		{"conflicting options", newBatchErr[struct {
+			V [][]proto.Point `ch:"v,polygon,multilinestring"`
+		}], "conflicting tag options [polygon multilinestring]"},
+		{"slice of floats", newBatchErr[struct {
+			V []float64 `ch:"v,ring"`
+		}], "Ring is not supported for Float64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.newBatch(), tt.err)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ClickHouse/ch-go"
 	"github.com/alphadose/haxmap"
+	"go.uber.org/multierr"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -43,15 +44,40 @@ type ClusterOptions[H Host] struct {
 	Global GlobalOptions
 }
 
+// InserterOption configures DistrInserter.
+type InserterOption func(opts *inserterOptions)
+
+type inserterOptions struct {
+	naming columnNaming
+}
+
+// WithNamingStrategy sets naming of columns and elements of Tuple columns
+// for fields without name in ch tag. SnakeCase is used by default.
+func WithNamingStrategy(strategy NamingStrategy) InserterOption {
+	return func(opts *inserterOptions) {
+		opts.naming.strategy = strategy
+	}
+}
+
+// WithTagFallback sets struct tags (e.g. "json", "db") which names are used in order
+// for fields without name in ch tag, before the naming strategy.
+func WithTagFallback(tags ...string) InserterOption {
+	return func(opts *inserterOptions) {
+		opts.naming.fallbackTags = tags
+	}
+}
+
 type shardWithChan[T any] struct {
 	shard *shard[T]
 	data  chan T
 }
 
 type DistrInserter[T any, H Host] struct {
-	cluster  ClusterOptions[H]
-	shards   *haxmap.Map[string, shardWithChan[T]]
-	selector HostSelector[H]
+	cluster   ClusterOptions[H]
+	shards    *haxmap.Map[string, shardWithChan[T]]
+	selector  HostSelector[H]
+	validator *batch[T]
+	naming    columnNaming
 
 	flushInterval    time.Duration
 	reconnectTimeout time.Duration
@@ -115,6 +141,11 @@ func makeCHOpts[H Host](global GlobalOptions, options Options[H]) ch.Options {
 	return chOpts
 }
 
+// Start connects to hosts and inserts pushed rows to table until ctx is done.
+// Columns of rows are checked with the table on each host before inserts,
+// mismatches are reported as ErrSchemaMismatch with the diff of columns.
+// Errors of shards, including JSON values which fail to marshal after Push
+// and are inserted as null, are passed to ShardErrHandler.
 func (ins *DistrInserter[T, H]) Start(ctx context.Context, table string) error {
 	stch := make(chan Host, len(ins.cluster.Hosts))
 	defer close(stch)
@@ -122,20 +153,35 @@ func (ins *DistrInserter[T, H]) Start(ctx context.Context, table string) error {
 	sharedBatches := make(chan *batch[T])
 	defer close(sharedBatches)
 
-	errg, ctx := errgroup.WithContext(ctx)
-
-	errg.Go(func() error {
-		return ListenStates[H](ctx, ins.selector, stch)
-	})
-
+	shards := make([]*shard[T], 0, len(ins.cluster.Hosts))
+	var schemaErr error
 	for _, nodeOpt := range ins.cluster.Hosts {
 		host := nodeOpt.Host
-		sh, err := newShard[T](ctx, host, makeCHOpts(ins.cluster.Global, nodeOpt))
+		sh, err := newShard[T](ctx, host, makeCHOpts(ins.cluster.Global, nodeOpt), ins.naming)
 		if err != nil {
 			return fmt.Errorf("create shard for host %s: %w", host.Info(), err)
 		}
 		defer sh.close()
+		sh.errHandler = ins.ShardErrHandler
+
+		// Schemas of all hosts are checked to report every mismatch at once.
+		schemaErr = multierr.Append(schemaErr, sh.checkSchema(ctx, table, ins.validator.input))
+		shards = append(shards, sh)
+	}
 
+	if schemaErr != nil {
+		return schemaErr
+	}
+
+	// States are listened after shards are created, since stch is closed on return.
+	errg, ctx := errgroup.WithContext(ctx)
+
+	errg.Go(func() error {
+		return ListenStates[H](ctx, ins.selector, stch)
+	})
+
+	for _, sh := range shards {
+		sh, host := sh, sh.host
 		data := make(chan T, 1)
 
 		ins.shards.Set(host.Info().ID(), struct {
@@ -174,7 +220,18 @@ func (ins *DistrInserter[T, H]) Start(ctx context.Context, table string) error {
 	return errg.Wait()
 }
 
+// Push sends row to one of shards. Rows which can not be inserted
+// (e.g. with unknown enum values) are rejected with ErrRowRejected,
+// nil rows of pointer type T are rejected with ErrNilRow.
 func (ins *DistrInserter[T, H]) Push(ctx context.Context, v T) error {
+	if isNilRow(v) {
+		return ErrNilRow
+	}
+
+	if err := ins.validator.check(v); err != nil {
+		return err
+	}
+
 	for {
 		h := ins.selector.Pick()
 		shinfo, ok := ins.shards.Get(h.ID())
@@ -199,11 +256,21 @@ func (ins *DistrInserter[T, H]) Push(ctx context.Context, v T) error {
 	}
 }
 
-func NewInserter[T any, H Host](cluster ClusterOptions[H], selector HostSelector[H]) (*DistrInserter[T, H], error) {
+func NewInserter[T any, H Host](cluster ClusterOptions[H], selector HostSelector[H], opts ...InserterOption) (*DistrInserter[T, H], error) {
 	if len(cluster.Hosts) == 0 {
 		return nil, errors.New("add options of hosts")
 	}
 
+	var options inserterOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	validator, err := newBatchWithNaming[T](options.naming)
+	if err != nil {
+		return nil, fmt.Errorf("batch init: %w", err)
+	}
+
 	for _, h := range cluster.Hosts {
 		if err := selector.AddHost(h.Host); err != nil {
 			return nil, fmt.Errorf("add host %s: %w", h.Host.Info(), err)
@@ -213,6 +280,8 @@ func NewInserter[T any, H Host](cluster ClusterOptions[H], selector HostSelector
 	return &DistrInserter[T, H]{
 		cluster:          cluster,
 		selector:         selector,
+		validator:        validator,
+		naming:           options.naming,
 		shards:           haxmap.New[string, shardWithChan[T]](),
 		flushInterval:    5 * time.Second,
 		reconnectTimeout: 2 * time.Second,
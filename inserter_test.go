@@ -111,3 +111,55 @@ func TestInserter(t *testing.T) {
 
 	assert.Equal(t, 1000, data.Rows())
 }
+
+func TestInserterDialError(t *testing.T) {
+	ins, err := NewInserter[testStruct, HostInfo](ClusterOptions[HostInfo]{
+		Hosts: []Options[HostInfo]{
+			{
+				Host: NewHostInfo("127.0.0.1:1", "default"),
+			},
+		},
+	}, RoundRobinSelector())
+	if err != nil {
+		t.Fatalf("new inserter: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = ins.Start(ctx, "table_insert")
+	assert.ErrorContains(t, err, "create shard for host")
+
+	// Shard loops are started only after all shards are created and checked.
+	assert.Zero(t, ins.shards.Len())
+}
+
+type testMismatchStruct struct {
+	Ts    time.Time
+	Foo   uint64
+	Extra string
+}
+
+func TestInserterSchemaMismatch(t *testing.T) {
+	ins, err := NewInserter[testMismatchStruct, HostInfo](ClusterOptions[HostInfo]{
+		Hosts: []Options[HostInfo]{
+			{
+				Host: NewHostInfo("127.0.0.1:9000", "default"),
+			},
+		},
+	}, RoundRobinSelector())
+	if err != nil {
+		t.Fatalf("new inserter: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	getTestConn(ctx, t)
+
+	err = ins.Start(ctx, "table_insert")
+	assert.ErrorIs(t, err, ErrSchemaMismatch)
+
+	// Shard loops are started only after all shards are created and checked.
+	assert.Zero(t, ins.shards.Len())
+}
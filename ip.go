@@ -0,0 +1,99 @@
+package chdistr
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+var (
+	netIPType         = reflect.TypeOf(net.IP(nil))
+	netipAddrType     = reflect.TypeOf(netip.Addr{})
+	netipAddrPortType = reflect.TypeOf(netip.AddrPort{})
+)
+
+var (
+	errInvalidIP = errors.New("invalid IP address")
+	errNotIPv4   = errors.New("not IPv4 address")
+)
+
+type ipGetter func(v any) netip.Addr
+
+// getIPGetter returns getter of address of net.IP, netip.Addr and netip.AddrPort values
+// or values of types derived from netip ones (e.g. `type ClientIP netip.Addr`).
+func getIPGetter(typ reflect.Type) (ipGetter, bool) {
+	var get ipGetter
+	switch {
+	case typ == netIPType:
+		typ, get = netIPType, func(v any) netip.Addr {
+			addr, _ := netip.AddrFromSlice(v.(net.IP))
+			return addr
+		}
+	case typ.ConvertibleTo(netipAddrType):
+		typ, get = netipAddrType, func(v any) netip.Addr {
+			return v.(netip.Addr)
+		}
+	case typ.ConvertibleTo(netipAddrPortType):
+		typ, get = netipAddrPortType, func(v any) netip.Addr {
+			return v.(netip.AddrPort).Addr()
+		}
+	default:
+		return nil, false
+	}
+
+	return func(v any) netip.Addr {
+		if rv := reflect.ValueOf(v); rv.Type() != typ {
+			v = rv.Convert(typ).Interface()
+		}
+		return get(v)
+	}, true
+}
+
+// getIPColAndAppender returns IPv4 column (by default) or IPv6 column with ipv6 tag option
+// for IP address types. IPv4 addresses are mapped to IPv6 as ::ffff:a.b.c.d,
+// IPv4-mapped IPv6 addresses are accepted by IPv4 column.
+func getIPColAndAppender(idx int, typ reflect.Type, opts tagOptions) (proto.ColInput, appender, checker, bool, error) {
+	get, ok := getIPGetter(typ)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if opts.Has("ipv6") {
+		if opts.Has("ipv4") {
+			return nil, nil, nil, false, errors.New("conflicting tag options ipv4, ipv6")
+		}
+
+		fn, check := newConvertingAppender(idx, ipv6Converter(get))
+		return &proto.ColIPv6{}, fn, check, true, nil
+	}
+
+	fn, check := newConvertingAppender(idx, ipv4Converter(get))
+	return &proto.ColIPv4{}, fn, check, true, nil
+}
+
+func ipv4Converter(get ipGetter) converter[proto.IPv4] {
+	return func(v any) (proto.IPv4, error) {
+		addr := get(v)
+		switch {
+		case !addr.IsValid():
+			return 0, errInvalidIP
+		case !addr.Unmap().Is4():
+			return 0, fmt.Errorf("%w %s", errNotIPv4, addr)
+		}
+		return proto.ToIPv4(addr.Unmap()), nil
+	}
+}
+
+func ipv6Converter(get ipGetter) converter[proto.IPv6] {
+	return func(v any) (proto.IPv6, error) {
+		addr := get(v)
+		if !addr.IsValid() {
+			return proto.IPv6{}, errInvalidIP
+		}
+		return proto.ToIPv6(addr), nil
+	}
+}
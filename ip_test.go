@@ -0,0 +1,89 @@
+package chdistr
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClientIP netip.Addr
+
+type testIP struct {
+	IP     net.IP
+	IPv6   net.IP         `ch:"ipv6,ipv6"`
+	Addr   netip.Addr     `ch:"addr,ipv6"`
+	Peer   netip.AddrPort `ch:"peer"`
+	Client testClientIP   `ch:"client,ipv4"`
+	Hops   []netip.Addr   `ch:"hops,ipv6"`
+	Opt    *netip.Addr
+}
+
+func TestBatchIP(t *testing.T) {
+	b, err := newBatch[testIP]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	row := testIP{
+		IP:     net.ParseIP("10.0.0.1"),
+		IPv6:   net.ParseIP("10.0.0.2"),
+		Addr:   netip.MustParseAddr("2001:db8::1"),
+		Peer:   netip.MustParseAddrPort("192.168.0.1:8080"),
+		Client: testClientIP(netip.MustParseAddr("::ffff:127.0.0.1")),
+		Hops:   []netip.Addr{netip.MustParseAddr("1.1.1.1")},
+	}
+	if !assert.Nil(t, b.check(row)) {
+		return
+	}
+	b.append(row)
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"IPv4",
+		"IPv6",
+		"IPv6",
+		"IPv4",
+		"IPv4",
+		"Array(IPv6)",
+		"Nullable(IPv4)",
+	}, types)
+
+	assert.Equal(t, "10.0.0.1", b.input[0].Data.(*proto.ColIPv4).Row(0).String())
+	assert.Equal(t, "::ffff:10.0.0.2", b.input[1].Data.(*proto.ColIPv6).Row(0).String())
+	assert.Equal(t, "2001:db8::1", b.input[2].Data.(*proto.ColIPv6).Row(0).String())
+	assert.Equal(t, "192.168.0.1", b.input[3].Data.(*proto.ColIPv4).Row(0).String())
+	assert.Equal(t, "127.0.0.1", b.input[4].Data.(*proto.ColIPv4).Row(0).String())
+
+	row.IP = net.ParseIP("::1")
+	assert.ErrorIs(t, b.check(row), ErrRowRejected)
+
+	row.IP = nil
+	assert.ErrorIs(t, b.check(row), ErrRowRejected)
+}
+
+func TestBatchInvalidIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		newBatch func() error
+		err      string
+	}{
+		{"conflicting options", newBatchErr[struct {
+			IP netip.Addr `ch:"ip,ipv4,ipv6"`
+		}], "conflicting tag options ipv4, ipv6"},
+		{"string", newBatchErr[struct {
+			IP string `ch:"ip,ipv6"`
+		}], "IPv6 is not supported for String"},
+		{"array of strings", newBatchErr[struct {
+			IPs []string `ch:"ips,ipv4"`
+		}], "IPv4 is not supported for String"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.newBatch(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package chdistr
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// colJSONStr is column of String, JSON or Object('json') type which values are sent as strings.
+// Server parses strings of JSON and Object('json') according to the serialization kind
+// written in the state prefix.
+//
+// Values are marshaled again on append, since rows are checked by another batch. Values which
+// fail to marshal on append (e.g. by MarshalJSON depending on state) are appended as null and
+// the error is kept until it is taken by the batch.
+type colJSONStr struct {
+	proto.ColStr
+
+	typ    proto.ColumnType
+	encode func(b *proto.Buffer)
+	err    error
+}
+
+func (c *colJSONStr) Type() proto.ColumnType {
+	return c.typ
+}
+
+func (c *colJSONStr) EncodeState(b *proto.Buffer) {
+	if c.encode != nil {
+		c.encode(b)
+	}
+}
+
+// takeErr returns marshal error of the last appended values and clears it.
+func (c *colJSONStr) takeErr() error {
+	err := c.err
+	c.err = nil
+	return err
+}
+
+// namedJSONColumn is JSON column of field or element, e.g. attrs.values of Map column attrs.
+type namedJSONColumn struct {
+	name string
+	col  *colJSONStr
+}
+
+// appendJSONColumns appends JSON columns of col and its elements to cols.
+func appendJSONColumns(cols []namedJSONColumn, name string, col proto.ColInput) []namedJSONColumn {
+	switch c := col.(type) {
+	case *colJSONStr:
+		cols = append(cols, namedJSONColumn{name: name, col: c})
+	case *valueColumn:
+		cols = appendJSONColumns(cols, name, c.ColInput)
+	case *nullableColumn:
+		cols = appendJSONColumns(cols, name, c.Values)
+	case *proto.ColArr[reflect.Value]:
+		cols = appendJSONColumns(cols, name, c.Data)
+	case *mapColumn:
+		cols = appendJSONColumns(cols, name+".keys", c.Keys)
+		cols = appendJSONColumns(cols, name+".values", c.Values)
+	case proto.ColTuple:
+		for _, elem := range c {
+			named := elem.(*proto.ColNamed[reflect.Value])
+			cols = appendJSONColumns(cols, name+"."+named.Name, named.ColumnOf)
+		}
+	}
+	return cols
+}
+
+// newJSONColumn creates column by encoding of json tag option:
+// String (string, by default), Object('json') (object) or JSON (json).
+func newJSONColumn(encoding string) (proto.ColInput, error) {
+	switch encoding {
+	case "", "string":
+		return &colJSONStr{typ: proto.ColumnTypeString}, nil
+	case "object":
+		return &colJSONStr{
+			typ: "Object('json')",
+			encode: func(b *proto.Buffer) {
+				// BinarySerializationKind::STRING
+				b.PutUInt8(1)
+			},
+		}, nil
+	case "json":
+		return &colJSONStr{
+			typ: "JSON",
+			encode: func(b *proto.Buffer) {
+				// ObjectSerializationVersion::STRING
+				b.PutUInt64(1)
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported JSON encoding %q", encoding)
+	}
+}
+
+// isJSONMap reports whether typ is map[string]any, which is stored as JSON without json tag option.
+func isJSONMap(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Map &&
+		typ.Key().Kind() == reflect.String &&
+		typ.Elem().Kind() == reflect.Interface && typ.Elem().NumMethod() == 0
+}
+
+// getJSONColAndAppender returns column of JSON serialized values for fields with json tag option,
+// map[string]any and json.RawMessage fields. json.RawMessage is appended as is, other values
+// are marshaled with encoding/json. Marshal errors and invalid raw messages are reported by checker,
+// errors of values which are checked, but fail on append are kept by the column.
+func getJSONColAndAppender(idx int, typ reflect.Type, opts tagOptions) (proto.ColInput, appender, checker, bool, error) {
+	encoding, ok := opts.Get("json")
+	if !ok && !isJSONMap(typ) && typ != rawMessageType {
+		return nil, nil, nil, false, nil
+	}
+
+	data, err := newJSONColumn(encoding)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	marshal := func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	if typ == rawMessageType {
+		marshal = func(v any) (string, error) {
+			raw := v.(json.RawMessage)
+			switch {
+			case len(raw) == 0:
+				return "null", nil
+			case !json.Valid(raw):
+				return "", fmt.Errorf("invalid JSON %q", raw)
+			}
+			return string(raw), nil
+		}
+	}
+
+	return data, func(v any, input proto.Input) {
+			col := input[idx].Data.(*colJSONStr)
+			s, err := marshal(v)
+			if err != nil {
+				col.err, s = err, "null"
+			}
+			col.Append(s)
+		}, func(v any) error {
+			_, err := marshal(v)
+			return err
+		}, true, nil
+}
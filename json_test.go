@@ -0,0 +1,135 @@
+package chdistr
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPayload struct {
+	Kind  string  `json:"kind"`
+	Value float64 `json:"value"`
+}
+
+type testJSON struct {
+	Attrs   map[string]any
+	Raw     json.RawMessage `ch:"raw,json"`
+	Payload testPayload     `ch:"payload,json"`
+	Object  testPayload     `ch:"object,json=object"`
+	Doc     map[string]any  `ch:"doc,json=json"`
+	Meta    json.RawMessage
+}
+
+func TestBatchJSON(t *testing.T) {
+	b, err := newBatch[testJSON]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	row := testJSON{
+		Attrs:   map[string]any{"a": 1, "b": []string{"c"}},
+		Payload: testPayload{"x", 0.5},
+		Object:  testPayload{"y", 1},
+		Doc:     map[string]any{"d": true},
+		Meta:    json.RawMessage(`{"m":1}`),
+	}
+	if !assert.Nil(t, b.check(row)) {
+		return
+	}
+	b.append(row)
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"String",
+		"String",
+		"String",
+		"Object('json')",
+		"JSON",
+		"String",
+	}, types)
+
+	assert.Equal(t, `{"a":1,"b":["c"]}`, b.input[0].Data.(*colJSONStr).Row(0))
+	assert.Equal(t, `null`, b.input[1].Data.(*colJSONStr).Row(0))
+	assert.Equal(t, `{"kind":"x","value":0.5}`, b.input[2].Data.(*colJSONStr).Row(0))
+	assert.Equal(t, `{"kind":"y","value":1}`, b.input[3].Data.(*colJSONStr).Row(0))
+	assert.Equal(t, `{"d":true}`, b.input[4].Data.(*colJSONStr).Row(0))
+	assert.Equal(t, `{"m":1}`, b.input[5].Data.(*colJSONStr).Row(0))
+
+	var buf proto.Buffer
+	b.input[4].Data.(proto.StateEncoder).EncodeState(&buf)
+	assert.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 0}, buf.Buf)
+
+	invalid := row
+	invalid.Raw = json.RawMessage("{")
+	assert.ErrorIs(t, b.check(invalid), ErrRowRejected)
+
+	invalid = row
+	invalid.Meta = json.RawMessage("{")
+	assert.ErrorIs(t, b.check(invalid), ErrRowRejected)
+
+	invalid = row
+	invalid.Attrs = map[string]any{"nan": math.NaN()}
+	assert.ErrorIs(t, b.check(invalid), ErrRowRejected)
+}
+
+// testFlaky fails to marshal after the first call.
+type testFlaky struct {
+	calls *int
+}
+
+func (f testFlaky) MarshalJSON() ([]byte, error) {
+	if *f.calls++; *f.calls > 1 {
+		return nil, errors.New("flaky")
+	}
+	return []byte(`"ok"`), nil
+}
+
+func TestBatchJSONAppendError(t *testing.T) {
+	b, err := newBatch[struct {
+		Flaky testFlaky `ch:"flaky,json"`
+	}]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	row := struct {
+		Flaky testFlaky `ch:"flaky,json"`
+	}{testFlaky{calls: new(int)}}
+	if !assert.Nil(t, b.check(row)) {
+		return
+	}
+
+	assert.ErrorContains(t, b.append(row), "column flaky: value is appended as null")
+	assert.Equal(t, "null", b.input[0].Data.(*colJSONStr).Row(0))
+
+	*row.Flaky.calls = 0
+	assert.Nil(t, b.append(row))
+}
+
+func TestBatchInvalidJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		newBatch func() error
+		err      string
+	}{
+		{"unknown encoding", newBatchErr[struct {
+			V map[string]any `ch:"v,json=yaml"`
+		}], `unsupported JSON encoding "yaml"`},
+		{"encoding of raw message", newBatchErr[struct {
+			V json.RawMessage `ch:"v,json=xml"`
+		}], `unsupported JSON encoding "xml"`},
+		{"low cardinality", newBatchErr[struct {
+			V map[string]any `ch:"v,lowcardinality"`
+		}], "tag option lowcardinality is not supported for String"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.newBatch(), tt.err)
+		})
+	}
+}
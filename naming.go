@@ -0,0 +1,57 @@
+package chdistr
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/goccy/go-reflect"
+)
+
+// NamingStrategy returns column name of struct field without name in ch tag.
+type NamingStrategy func(field string) string
+
+var (
+	// SnakeCase maps UserIDHash to user_id_hash. It is used by default.
+	SnakeCase NamingStrategy = toUnderScore
+	// CamelCase maps UserIDHash to userIDHash.
+	CamelCase NamingStrategy = toCamelCase
+	// ExactCase uses field name as is.
+	ExactCase NamingStrategy = func(field string) string { return field }
+	// LowerCase maps UserIDHash to useridhash.
+	LowerCase NamingStrategy = strings.ToLower
+)
+
+// columnNaming resolves column names of fields.
+type columnNaming struct {
+	strategy NamingStrategy
+	// fallbackTags are struct tags (e.g. json and db) which names are used if ch tag has no name.
+	fallbackTags []string
+}
+
+// name returns column name of field without name in ch tag by fallback tags or the strategy.
+// Fields named "-" by a fallback tag are skipped like fields with `ch:"-"`.
+func (n columnNaming) name(field reflect.StructField) string {
+	for _, key := range n.fallbackTags {
+		if name, _, _ := strings.Cut(field.Tag.Get(key), ","); name != "" {
+			return name
+		}
+	}
+
+	if n.strategy == nil {
+		return SnakeCase(field.Name)
+	}
+	return n.strategy(field.Name)
+}
+
+// toCamelCase lowers the first word of name, e.g. UserIDHash to userIDHash and HTTPServer to httpServer.
+func toCamelCase(name string) string {
+	rs := []rune(name)
+	for i := 0; i < len(rs) && unicode.IsUpper(rs[i]); i++ {
+		// Last upper letter of acronym starts the next word.
+		if i > 0 && i+1 < len(rs) && unicode.IsLower(rs[i+1]) {
+			break
+		}
+		rs[i] = unicode.ToLower(rs[i])
+	}
+	return string(rs)
+}
@@ -0,0 +1,101 @@
+package chdistr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testNaming struct {
+	UserIDHash uint64
+	HTTPServer string `db:"server"`
+	Region     string `json:"region,omitempty" ch:",lowcardinality"`
+	Secret     string `json:"-"`
+	Named      string `ch:"explicit" json:"ignored"`
+	TestMeta
+	Attrs []testAttr `ch:",nested"`
+}
+
+func TestBatchNaming(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		naming columnNaming
+		want   []string
+	}{
+		{
+			name:   "default",
+			naming: columnNaming{},
+			want:   []string{"user_id_hash", "http_server", "region", "secret", "explicit", "host", "version", "attrs.key", "attrs.value"},
+		},
+		{
+			name:   "camel",
+			naming: columnNaming{strategy: CamelCase},
+			want:   []string{"userIDHash", "httpServer", "region", "secret", "explicit", "host", "version", "attrs.key", "attrs.value"},
+		},
+		{
+			name:   "exact with fallback",
+			naming: columnNaming{strategy: ExactCase, fallbackTags: []string{"json", "db"}},
+			want:   []string{"UserIDHash", "server", "region", "explicit", "Host", "Version", "Attrs.Key", "Attrs.Value"},
+		},
+		{
+			name:   "lower",
+			naming: columnNaming{strategy: LowerCase},
+			want:   []string{"useridhash", "httpserver", "region", "secret", "explicit", "host", "version", "attrs.key", "attrs.value"},
+		},
+		{
+			name:   "custom",
+			naming: columnNaming{strategy: func(field string) string { return "c_" + strings.ToLower(field) }},
+			want:   []string{"c_useridhash", "c_httpserver", "c_region", "c_secret", "explicit", "c_host", "c_version", "c_attrs.c_key", "c_attrs.c_value"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := newBatchWithNaming[testNaming](tc.naming)
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			names := make([]string, 0, len(b.input))
+			for _, inp := range b.input {
+				names = append(names, inp.Name)
+			}
+			assert.Equal(t, tc.want, names)
+		})
+	}
+}
+
+type testTupleNaming struct {
+	Meta  TestMeta
+	Metas []TestMeta
+	Point struct {
+		Lat float64 `json:"latitude"`
+		Lon float64
+	}
+}
+
+func TestBatchTupleNaming(t *testing.T) {
+	b, err := newBatchWithNaming[testTupleNaming](columnNaming{strategy: ExactCase, fallbackTags: []string{"json"}})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	types := columnTypes(t, b.input, 0)
+	assert.Equal(t, []proto.ColumnType{
+		"Tuple(Host String, Version UInt32)",
+		"Array(Tuple(Host String, Version UInt32))",
+		"Tuple(latitude Float64, Lon Float64)",
+	}, types)
+}
+
+func TestCamelCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"UserIDHash": "userIDHash",
+		"ID":         "id",
+		"HTTPServer": "httpServer",
+		"Foo":        "foo",
+		"fooBar":     "fooBar",
+	} {
+		assert.Equal(t, want, toCamelCase(name))
+	}
+}
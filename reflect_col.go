@@ -1,35 +1,115 @@
 package chdistr
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/ClickHouse/ch-go/proto"
 	"github.com/goccy/go-reflect"
 	"github.com/google/uuid"
 )
 
+var (
+	bytesType = reflect.TypeOf([]byte(nil))
+	byteType  = reflect.TypeOf(byte(0))
+)
+
 type appender func(v any, input proto.Input)
 
+// checker validates value before it is appended, so rejected row
+// never leaves columns of batch with different number of rows.
+// Appenders don't report errors: values which are not checked, e.g. zero
+// values appended for NULL of Nullable, are appended as zero value of column.
+type checker func(v any) error
+
+// converter converts value to value of column, zero value is returned with error.
+type converter[T any] func(v any) (T, error)
+
+// newConvertingAppender returns appender and checker of values converted by convert,
+// so values are converted in one place. Column must have Append(T) method.
+func newConvertingAppender[T any](idx int, convert converter[T]) (appender, checker) {
+	return func(v any, input proto.Input) {
+			val, _ := convert(v)
+			input[idx].Data.(interface{ Append(v T) }).Append(val)
+		}, func(v any) error {
+			_, err := convert(v)
+			return err
+		}
+}
+
 func newAppender[T any](idx int) appender {
 	return func(v any, input proto.Input) {
 		input[idx].Data.(proto.ColumnOf[T]).Append(v.(T))
 	}
 }
 
-func newUIntAppender[T uint32 | uint64](idx int) appender {
-	return func(v any, input proto.Input) {
-		input[idx].Data.(proto.ColumnOf[T]).Append(T(v.(uint)))
+// fieldAppender appends field of row, which is read by pointer ptr without boxing to any.
+// ptr is nil if the field is promoted through nil embedded pointer, then zero value is appended.
+type fieldAppender func(ptr unsafe.Pointer, input proto.Input)
+
+// newFieldAppender appends fields which memory layout is T, e.g. fields of named types
+// (`type UserID uint64`) and int (int64 or int32 by platform).
+func newFieldAppender[T any](idx int) fieldAppender {
+	return func(ptr unsafe.Pointer, input proto.Input) {
+		var v T
+		if ptr != nil {
+			v = *(*T)(ptr)
+		}
+		input[idx].Data.(proto.ColumnOf[T]).Append(v)
 	}
 }
 
-func newIntAppender[T int32 | int64](idx int) appender {
+// newBytesFieldAppender appends byte slices (and named byte slices) to String column.
+func newBytesFieldAppender(idx int) fieldAppender {
+	return func(ptr unsafe.Pointer, input proto.Input) {
+		var v []byte
+		if ptr != nil {
+			v = *(*[]byte)(ptr)
+		}
+		input[idx].Data.(*proto.ColStr).AppendBytes(v)
+	}
+}
+
+// newReflectFieldAppender appends fields of typ with appender of boxed values.
+func newReflectFieldAppender(typ reflect.Type, fn appender) fieldAppender {
+	return func(ptr unsafe.Pointer, input proto.Input) {
+		if ptr == nil {
+			fn(reflect.Zero(typ).Interface(), input)
+			return
+		}
+		fn(reflect.NewAt(typ, ptr).Elem().Interface(), input)
+	}
+}
+
+// newKindAppender appends values of typ which kind is the kind of T.
+// Named types (e.g. `type UserID uint64`) and platform dependent int and uint
+// are converted to T, values of type T are appended as is.
+func newKindAppender[T any](idx int, typ reflect.Type, conv func(v reflect.Value) T) appender {
+	var zero T
+	if typ == reflect.TypeOf(zero) {
+		return newAppender[T](idx)
+	}
+
 	return func(v any, input proto.Input) {
-		input[idx].Data.(proto.ColumnOf[T]).Append(T(v.(int)))
+		input[idx].Data.(proto.ColumnOf[T]).Append(conv(reflect.ValueOf(v)))
 	}
 }
 
+func uintValue[T uint8 | uint16 | uint32 | uint64](v reflect.Value) T { return T(v.Uint()) }
+
+func intValue[T int8 | int16 | int32 | int64](v reflect.Value) T { return T(v.Int()) }
+
+func floatValue[T float32 | float64](v reflect.Value) T { return T(v.Float()) }
+
+func boolValue(v reflect.Value) bool { return v.Bool() }
+
+func stringValue(v reflect.Value) string { return v.String() }
+
 type chDates interface {
 	proto.Date | proto.Date32 | proto.DateTime | proto.DateTime64
 }
@@ -50,114 +130,1005 @@ func precisionDateToTime[T interface {
 	return t.Time(proto.PrecisionMax)
 }
 
-func getColAndAppenderFromField(name string, idx int, field reflect.StructField) (col proto.InputColumn, fn appender, err error) {
+// timeLocation returns location of tz tag option.
+func timeLocation(opts tagOptions) (*time.Location, error) {
+	tz, ok := opts.Get("tz")
+	if !ok {
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("load location: %w", err)
+	}
+	return loc, nil
+}
+
+// dateTime64Precision returns precision of datetime64=P tag option or proto.PrecisionMax.
+func dateTime64Precision(opts tagOptions) (proto.Precision, bool, error) {
+	val, ok := opts.Get("datetime64")
+	if !ok {
+		return proto.PrecisionMax, false, nil
+	}
+
+	p, err := strconv.ParseUint(val, 10, 8)
+	if err != nil || !proto.Precision(p).Valid() {
+		return 0, false, fmt.Errorf("invalid DateTime64 precision %q", val)
+	}
+	return proto.Precision(p), true, nil
+}
+
+// newTimeColumn creates column for time.Time by tag options:
+// date, date32, datetime (by default) or datetime64=P.
+func newTimeColumn(opts tagOptions, loc *time.Location) (proto.ColInput, error) {
+	var set []string
+	for _, opt := range []string{"date", "date32", "datetime", "datetime64"} {
+		if opts.Has(opt) {
+			set = append(set, opt)
+		}
+	}
+	if len(set) > 1 {
+		return nil, fmt.Errorf("conflicting tag options %s", strings.Join(set, ", "))
+	}
+
+	switch {
+	case opts.Has("date"):
+		return &proto.ColDate{}, nil
+	case opts.Has("date32"):
+		return &proto.ColDate32{}, nil
+	case opts.Has("datetime64"):
+		precision, _, err := dateTime64Precision(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return &proto.ColDateTime64{
+			Precision:    precision,
+			PrecisionSet: true,
+			Location:     loc,
+		}, nil
+	default:
+		return &proto.ColDateTime{Location: loc}, nil
+	}
+}
+
+// newTimeAppender appends time in loc, so Date and Date32 columns get the day in loc.
+func newTimeAppender(idx int, loc *time.Location) appender {
+	if loc == nil {
+		return newAppender[time.Time](idx)
+	}
+
+	return func(v any, input proto.Input) {
+		input[idx].Data.(proto.ColumnOf[time.Time]).Append(v.(time.Time).In(loc))
+	}
+}
+
+// valueColumn adapts column of any supported type to proto.ColumnOf[reflect.Value],
+// so it can be wrapped by generic ch-go columns (e.g. proto.ColNullable).
+// Values are appended with the appender of wrapped column. Column is write-only.
+type valueColumn struct {
+	proto.ColInput
+
+	input proto.Input
+	fn    appender
+	check checker
+}
+
+func newValueColumn(field reflect.StructField, typ reflect.Type, naming columnNaming) (*valueColumn, error) {
+	field.Type = typ
+	col, fn, check, _, err := getColAndAppenderFromField(field.Name, 0, field, naming)
+	if err != nil {
+		return nil, err
+	}
+
+	return &valueColumn{
+		ColInput: col.Data,
+		input:    proto.Input{col},
+		fn:       fn,
+		check:    check,
+	}, nil
+}
+
+func (c *valueColumn) Append(v reflect.Value) {
+	c.fn(v.Interface(), c.input)
+}
+
+func (c *valueColumn) Row(i int) reflect.Value {
+	panic("chdistr: value column is write-only")
+}
+
+func (c *valueColumn) DecodeColumn(r *proto.Reader, rows int) error {
+	return errors.New("value column is write-only")
+}
+
+func (c *valueColumn) Reset() {
+	if v, ok := c.ColInput.(proto.Resettable); ok {
+		v.Reset()
+	}
+}
+
+func (c *valueColumn) EncodeState(b *proto.Buffer) {
+	if v, ok := c.ColInput.(proto.StateEncoder); ok {
+		v.EncodeState(b)
+	}
+}
+
+func (c *valueColumn) Prepare() error {
+	if v, ok := c.ColInput.(proto.Preparable); ok {
+		return v.Prepare()
+	}
+	return nil
+}
+
+func (c *valueColumn) Infer(t proto.ColumnType) error {
+	if v, ok := c.ColInput.(proto.Inferable); ok {
+		return v.Infer(t)
+	}
+	return nil
+}
+
+var errNestedNullable = errors.New("nested nullable is not supported")
+
+// nullableGetter gets value of nullable field and reports whether it is set.
+type nullableGetter func(v reflect.Value) (reflect.Value, bool)
+
+// getNullableColAndAppender creates Nullable(T) column for values of elem type,
+// which are got from the field value by get.
+func getNullableColAndAppender(idx int, field reflect.StructField, elem reflect.Type, get nullableGetter, naming columnNaming) (proto.ColInput, appender, checker, error) {
+	if _, opts := parseTag(field.Tag.Get("ch")); opts.Has("lowcardinality") {
+		if format, ok := getTextFormatter(elem, true); ok && opts.Has("stringer") {
+			return newColLowCardinalityNullable[string](&proto.ColStr{}), newLowCardinalityNullableAppender(idx, get, func(v reflect.Value) string {
+				s, _ := format(v.Interface())
+				return s
+			}), nil, nil
+		}
+
+		if elem.Kind() == reflect.String && !opts.Has("stringer") {
+			return newColLowCardinalityNullable[string](&proto.ColStr{}), newLowCardinalityNullableAppender(idx, get, reflect.Value.String), nil, nil
+		}
+	}
+
+	data, err := newNullableColumn(field, elem, naming)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return data, newNullableAppender(idx, elem, get), newNullableChecker(data.Values.(*valueColumn).check, get), nil
+}
+
+// nullableColumn is Nullable(T) column which prepares and infers values, e.g. codes of Enum.
+type nullableColumn struct {
+	*proto.ColNullable[reflect.Value]
+}
+
+func (c *nullableColumn) Prepare() error {
+	return c.Values.(*valueColumn).Prepare()
+}
+
+func (c *nullableColumn) Infer(t proto.ColumnType) error {
+	base, args := splitType(t)
+	if base != proto.ColumnTypeNullable || len(args) != 1 {
+		return fmt.Errorf("invalid nullable type %s", t)
+	}
+	return c.Values.(*valueColumn).Infer(args[0])
+}
+
+// newNullableColumn wraps column of elem type to Nullable(T).
+func newNullableColumn(field reflect.StructField, elem reflect.Type, naming columnNaming) (*nullableColumn, error) {
+	values, err := newValueColumn(field, elem, naming)
+	if err != nil {
+		return nil, err
+	}
+
+	switch base := values.Type().Base(); base {
+	case proto.ColumnTypeNullable:
+		return nil, fmt.Errorf("field %s: %w", field.Name, errNestedNullable)
+	case proto.ColumnTypeArray, proto.ColumnTypeMap, proto.ColumnTypeTuple:
+		return nil, fmt.Errorf("field %s: Nullable(%s) is not supported", field.Name, base)
+	}
+
+	return &nullableColumn{proto.NewColNullable[reflect.Value](values)}, nil
+}
+
+// newNullableAppender appends value returned by get, or NULL if value is not set.
+func newNullableAppender(idx int, elem reflect.Type, get nullableGetter) appender {
+	zero := reflect.Zero(elem)
+	return func(v any, input proto.Input) {
+		val, ok := get(reflect.ValueOf(v))
+		if !ok {
+			val = zero
+		}
+
+		input[idx].Data.(*nullableColumn).Append(proto.Nullable[reflect.Value]{
+			Set:   ok,
+			Value: val,
+		})
+	}
+}
+
+func newNullableChecker(check checker, get nullableGetter) checker {
+	if check == nil {
+		return nil
+	}
+
+	return func(v any) error {
+		if val, ok := get(reflect.ValueOf(v)); ok {
+			return check(val.Interface())
+		}
+		return nil
+	}
+}
+
+func newLowCardinalityNullableAppender(idx int, get nullableGetter, str func(v reflect.Value) string) appender {
+	return func(v any, input proto.Input) {
+		var val proto.Nullable[string]
+		if s, ok := get(reflect.ValueOf(v)); ok {
+			val = proto.NewNullable(str(s))
+		}
+
+		input[idx].Data.(*colLowCardinalityNullable[string]).Append(val)
+	}
+}
+
+func ptrValue(v reflect.Value) (reflect.Value, bool) {
+	if v.IsNil() {
+		return reflect.Value{}, false
+	}
+	return v.Elem(), true
+}
+
+// sqlNullValue gets value of database/sql Null types (sql.NullString, sql.NullInt64 and etc).
+func sqlNullValue(v reflect.Value) (reflect.Value, bool) {
+	return v.Field(0), v.Field(1).Bool()
+}
+
+func isSQLNullType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct &&
+		strings.HasPrefix(typ.Name(), "Null") &&
+		typ.NumField() == 2 &&
+		typ.Field(1).Name == "Valid" &&
+		typ.Field(1).Type.Kind() == reflect.Bool
+}
+
+// newArrayColumn creates Array(T) column for slices of elem type.
+func newArrayColumn(field reflect.StructField, elem reflect.Type, naming columnNaming) (*proto.ColArr[reflect.Value], error) {
+	data, err := newValueColumn(field, elem, naming)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.NewArray[reflect.Value](data), nil
+}
+
+func newArrayChecker(check checker) checker {
+	if check == nil {
+		return nil
+	}
+
+	return func(v any) error {
+		rv := reflect.ValueOf(v)
+		for i := 0; i < rv.Len(); i++ {
+			if err := check(rv.Index(i).Interface()); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return nil
+	}
+}
+
+func newArrayAppender(idx int) appender {
+	return func(v any, input proto.Input) {
+		arr := input[idx].Data.(*proto.ColArr[reflect.Value])
+		rv := reflect.ValueOf(v)
+		for i := 0; i < rv.Len(); i++ {
+			arr.Data.Append(rv.Index(i))
+		}
+		arr.Offsets.Append(uint64(arr.Data.Rows()))
+	}
+}
+
+// mapColumn is Map(K, V) column which prepares and infers keys and values,
+// e.g. dictionaries of LowCardinality and codes of Enum.
+type mapColumn struct {
+	*proto.ColMap[reflect.Value, reflect.Value]
+}
+
+func (c *mapColumn) Prepare() error {
+	if err := c.Keys.(*valueColumn).Prepare(); err != nil {
+		return err
+	}
+	return c.Values.(*valueColumn).Prepare()
+}
+
+func (c *mapColumn) Infer(t proto.ColumnType) error {
+	base, args := splitType(t)
+	if base != proto.ColumnTypeMap || len(args) != 2 {
+		return fmt.Errorf("invalid map type %s", t)
+	}
+
+	if err := c.Keys.(*valueColumn).Infer(args[0]); err != nil {
+		return err
+	}
+	return c.Values.(*valueColumn).Infer(args[1])
+}
+
+// newMapColumn creates Map(K, V) column for maps with key and elem types.
+func newMapColumn(field, valueField reflect.StructField, key, elem reflect.Type, naming columnNaming) (*mapColumn, error) {
+	keys, err := newValueColumn(field, key, naming)
+	if err != nil {
+		return nil, err
+	}
+
+	switch base := keys.Type().Base(); base {
+	case proto.ColumnTypeNullable, proto.ColumnTypeArray, proto.ColumnTypeMap:
+		return nil, fmt.Errorf("field %s: map key %s is not supported", field.Name, keys.Type())
+	}
+
+	values, err := newValueColumn(valueField, elem, naming)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mapColumn{proto.NewMap[reflect.Value, reflect.Value](keys, values)}, nil
+}
+
+// newMapAppender appends map in order of iteration or, if sorted is set, in ascending order of keys.
+func newMapAppender(idx int, sorted bool) appender {
+	return func(v any, input proto.Input) {
+		m := input[idx].Data.(*mapColumn)
+		rv := reflect.ValueOf(v)
+
+		keys := rv.MapKeys()
+		if sorted {
+			sort.Slice(keys, func(i, j int) bool {
+				return lessValue(keys[i], keys[j])
+			})
+		}
+
+		for _, k := range keys {
+			m.Keys.Append(k)
+			m.Values.Append(rv.MapIndex(k))
+		}
+		m.Offsets.Append(uint64(m.Keys.Rows()))
+	}
+}
+
+func newMapChecker(checkKey, checkValue checker) checker {
+	if checkKey == nil && checkValue == nil {
+		return nil
+	}
+
+	return func(v any) error {
+		iter := reflect.ValueOf(v).MapRange()
+		for iter.Next() {
+			if checkKey != nil {
+				if err := checkKey(iter.Key().Interface()); err != nil {
+					return fmt.Errorf("key %v: %w", iter.Key(), err)
+				}
+			}
+
+			if checkValue != nil {
+				if err := checkValue(iter.Value().Interface()); err != nil {
+					return fmt.Errorf("[%v]: %w", iter.Key(), err)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func lessValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	default:
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	}
+}
+
+// isPlainStruct reports whether typ is a struct which is mapped to Tuple column
+// (or flattened, if embedded), unlike structs with dedicated column types
+// such as time.Time, sql.NullString or proto.Point.
+func isPlainStruct(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+
+	switch typ.PkgPath() {
+	case "time", "database/sql", "github.com/ClickHouse/ch-go/proto":
+		return false
+	}
+	return true
+}
+
+// newTupleColumn creates named Tuple(...) column for struct of typ.
+func newTupleColumn(field reflect.StructField, typ reflect.Type, naming columnNaming) (proto.ColTuple, []structField, error) {
+	if refersTo(typ, typ, map[reflect.Type]struct{}{}) {
+		return nil, nil, fmt.Errorf("field %s: recursive type %s is not supported", field.Name, typ)
+	}
+
+	fields := getStructInfo(reflect.New(typ).Elem(), naming)
+	if len(fields) == 0 {
+		return nil, nil, fmt.Errorf("field %s: struct %s has no exported fields", field.Name, typ)
+	}
+
+	if err := checkEmbeddedTags(typ, map[reflect.Type]struct{}{typ: {}}); err != nil {
+		return nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+	}
+
+	tuple := make(proto.ColTuple, 0, len(fields))
+	for _, f := range fields {
+		data, err := newValueColumn(f.StructField, f.Type, naming)
+		if err == nil {
+			err = checkTagOptions(f.StructField, data.Type())
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		tuple = append(tuple, proto.Named[reflect.Value](data, f.column))
+	}
+
+	return tuple, fields, nil
+}
+
+func newTupleAppender(idx int, fields []structField) appender {
+	return func(v any, input proto.Input) {
+		tuple := input[idx].Data.(proto.ColTuple)
+		rv := reflect.ValueOf(v)
+		for i, f := range fields {
+			tuple[i].(*proto.ColNamed[reflect.Value]).Append(f.value(rv))
+		}
+	}
+}
+
+func newTupleChecker(tuple proto.ColTuple, fields []structField) checker {
+	checks := make([]checker, len(fields))
+	var hasChecks bool
+	for i, col := range tuple {
+		checks[i] = col.(*proto.ColNamed[reflect.Value]).ColumnOf.(*valueColumn).check
+		hasChecks = hasChecks || checks[i] != nil
+	}
+
+	if !hasChecks {
+		return nil
+	}
+
+	return func(v any) error {
+		rv := reflect.ValueOf(v)
+		for i, f := range fields {
+			if checks[i] == nil {
+				continue
+			}
+
+			if err := checks[i](f.value(rv).Interface()); err != nil {
+				return fmt.Errorf("%s: %w", f.column, err)
+			}
+		}
+		return nil
+	}
+}
+
+// getNestedColsAndAppender expands slice of structs to Array columns of Nested(...) structure
+// named as name.column. Columns are appended starting from idx.
+func getNestedColsAndAppender(name string, idx int, field reflect.StructField, naming columnNaming) ([]proto.InputColumn, appender, checker, error) {
+	typ := field.Type
+	if typ.Kind() != reflect.Slice {
+		return nil, nil, nil, fmt.Errorf("field %s: Nested requires slice of structs, got %s", field.Name, typ)
+	}
+
+	elem := typ.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if !isPlainStruct(elem) {
+		return nil, nil, nil, fmt.Errorf("field %s: Nested requires slice of structs, got %s", field.Name, typ)
+	}
+
+	if err := checkTagOptions(field, "Nested"); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := checkEmbeddedTags(elem, map[reflect.Type]struct{}{elem: {}}); err != nil {
+		return nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+	}
+
+	fields := getStructInfo(reflect.New(elem).Elem(), naming)
+	if len(fields) == 0 {
+		return nil, nil, nil, fmt.Errorf("field %s: struct %s has no exported fields", field.Name, elem)
+	}
+
+	cols := make([]proto.InputColumn, 0, len(fields))
+	checks := make([]checker, len(fields))
+	var hasChecks bool
+	for i, f := range fields {
+		arr, err := newArrayColumn(f.StructField, f.Type, naming)
+		if err == nil {
+			err = checkTagOptions(f.StructField, arr.Data.Type())
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		cols = append(cols, proto.InputColumn{
+			Name: name + "." + f.column,
+			Data: arr,
+		})
+
+		checks[i] = arr.Data.(*valueColumn).check
+		hasChecks = hasChecks || checks[i] != nil
+	}
+
+	var check checker
+	if hasChecks {
+		check = newNestedChecker(fields, checks)
+	}
+
+	return cols, newNestedAppender(idx, fields), check, nil
+}
+
+// nestedElems calls fn for each struct element of slice v, nil pointers are treated as zero structs.
+func nestedElems(v reflect.Value, fn func(i int, elem reflect.Value) error) error {
+	var zero reflect.Value
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				if !zero.IsValid() {
+					zero = reflect.Zero(elem.Type().Elem())
+				}
+				elem = zero
+			} else {
+				elem = elem.Elem()
+			}
+		}
+
+		if err := fn(i, elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newNestedAppender appends fields of slice elements to Array columns, so the arrays
+// of the row have the same length.
+func newNestedAppender(idx int, fields []structField) appender {
+	return func(v any, input proto.Input) {
+		rv := reflect.ValueOf(v)
+		for i, f := range fields {
+			arr := input[idx+i].Data.(*proto.ColArr[reflect.Value])
+			_ = nestedElems(rv, func(_ int, elem reflect.Value) error {
+				arr.Data.Append(f.value(elem))
+				return nil
+			})
+			arr.Offsets.Append(uint64(arr.Data.Rows()))
+		}
+	}
+}
+
+func newNestedChecker(fields []structField, checks []checker) checker {
+	return func(v any) error {
+		return nestedElems(reflect.ValueOf(v), func(i int, elem reflect.Value) error {
+			for j, f := range fields {
+				if checks[j] == nil {
+					continue
+				}
+
+				if err := checks[j](f.value(elem).Interface()); err != nil {
+					return fmt.Errorf("[%d].%s: %w", i, f.column, err)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// refersTo reports whether typ refers to target through its elements or fields.
+func refersTo(typ, target reflect.Type, visited map[reflect.Type]struct{}) bool {
+	refers := func(t reflect.Type) bool {
+		if t == target {
+			return true
+		}
+
+		if _, ok := visited[t]; ok {
+			return false
+		}
+		visited[t] = struct{}{}
+
+		return refersTo(t, target, visited)
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return refers(typ.Elem())
+	case reflect.Map:
+		return refers(typ.Key()) || refers(typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if refers(typ.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newBytesAppender(idx int, typ reflect.Type) appender {
+	if typ == bytesType {
+		return func(v any, input proto.Input) {
+			input[idx].Data.(*proto.ColStr).AppendBytes(v.([]byte))
+		}
+	}
+
+	return func(v any, input proto.Input) {
+		input[idx].Data.(*proto.ColStr).AppendBytes(reflect.ValueOf(v).Bytes())
+	}
+}
+
+// fixedStrSize returns N of fixedstring=N tag option.
+func fixedStrSize(opts tagOptions) (int, bool, error) {
+	val, ok := opts.Get("fixedstring")
+	if !ok {
+		return 0, false, nil
+	}
+
+	size, err := strconv.Atoi(val)
+	if err != nil || size <= 0 {
+		return 0, false, fmt.Errorf("invalid FixedString size %q", val)
+	}
+	return size, true, nil
+}
+
+// newFixedStrAppender appends strings and byte slices to FixedString(N) column.
+// Longer values are truncated, shorter are padded with zero bytes.
+func newFixedStrAppender(idx, size int) appender {
+	buf := make([]byte, size)
+	return func(v any, input proto.Input) {
+		var n int
+		switch s := v.(type) {
+		case string:
+			n = copy(buf, s)
+		case []byte:
+			n = copy(buf, s)
+		default:
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+				n = copy(buf, rv.String())
+			} else {
+				n = copy(buf, rv.Bytes())
+			}
+		}
+
+		for i := n; i < size; i++ {
+			buf[i] = 0
+		}
+		input[idx].Data.(*proto.ColFixedStr).Append(buf)
+	}
+}
+
+// newByteArrayAppender appends [N]byte arrays to FixedString(N) column.
+func newByteArrayAppender(idx, size int) appender {
+	buf := make([]byte, size)
+	dst := reflect.ValueOf(buf)
+	return func(v any, input proto.Input) {
+		reflect.Copy(dst, reflect.ValueOf(v))
+		input[idx].Data.(*proto.ColFixedStr).Append(buf)
+	}
+}
+
+// convertingColumn returns column for type which values are converted to the column type,
+// ok is false if the type or its tag options are not supported.
+type convertingColumn func(idx int, typ reflect.Type, opts tagOptions) (data proto.ColInput, fn appender, check checker, ok bool, err error)
+
+// convertingColumns take precedence over mappings by kind.
+var convertingColumns = []convertingColumn{
+	getIPColAndAppender,
+	getJSONColAndAppender,
+	getBigIntColAndAppender,
+	getDecimalColAndAppender,
+	getGeoColAndAppender,
+}
+
+// getColAndAppenderFromField returns column of field, direct appender is nil
+// if the field values can't be read without boxing.
+func getColAndAppenderFromField(name string, idx int, field reflect.StructField, naming columnNaming) (proto.InputColumn, appender, checker, fieldAppender, error) {
+	_, opts := parseTag(field.Tag.Get("ch"))
+	if !opts.Has("type") {
+		return getColAndAppender(name, idx, field, opts, typeTag{elem: field, key: field, value: field}, naming)
+	}
+
+	tag, err := resolveTypeTag(field, opts)
+	if err != nil {
+		return proto.InputColumn{}, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+	}
+
+	col, fn, check, direct, err := getColAndAppender(name, idx, field, opts, tag, naming)
+	if err != nil {
+		return col, nil, nil, nil, err
+	}
+
+	if err := checkTypeTag(tag.declared, col.Data.Type()); err != nil {
+		return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+	}
+	return col, fn, check, direct, nil
+}
+
+// getColAndAppender returns column of field type selected by tag options. Columns of Nullable
+// and Array elements, Map keys and values are created by fields of type tag.
+// Direct appender is returned for columns of primitive and fixed size types,
+// which values are read from the row by pointer.
+func getColAndAppender(name string, idx int, field reflect.StructField, opts tagOptions, tag typeTag, naming columnNaming) (col proto.InputColumn, fn appender, check checker, direct fieldAppender, err error) {
 	var data proto.ColInput
 	typ := field.Type
+
+	if opts.Has("nested") {
+		return col, nil, nil, nil, fmt.Errorf("field %s: Nested is supported only for fields of row", field.Name)
+	}
+
+	if data, fn, ok := getCustomColAndAppender(idx, typ); ok {
+		return proto.InputColumn{
+			Name: name,
+			Data: data,
+		}, fn, nil, nil, nil
+	}
+
+	// Pointers are Nullable, String can't be called on nil.
+	if opts.Has("stringer") && typ.Kind() != reflect.Ptr {
+		data, fn, _, ok := getTextColAndAppender(idx, typ, opts)
+		if !ok {
+			return col, nil, nil, nil, fmt.Errorf("field %s: type %s doesn't implement fmt.Stringer", field.Name, typ)
+		}
+
+		return proto.InputColumn{
+			Name: name,
+			Data: data,
+		}, fn, nil, nil, nil
+	}
+
+	var enumBase proto.ColumnType
+	switch {
+	case opts.Has("enum8"):
+		enumBase = proto.ColumnTypeEnum8
+	case opts.Has("enum16"):
+		enumBase = proto.ColumnTypeEnum16
+	}
+
+	if info, ok := lookupEnum(typ); ok && enumBase != "" {
+		enum, err := newEnumColumn(info, enumBase)
+		if err != nil {
+			return col, nil, nil, nil, fmt.Errorf("field %s: enum %s: %w", field.Name, typ, err)
+		}
+
+		return proto.InputColumn{
+			Name: name,
+			Data: enum,
+		}, newEnumAppender(idx, info), newEnumChecker(info), nil, nil
+	}
+
+	for _, get := range convertingColumns {
+		if data, fn, check, ok, err := get(idx, typ, opts); err != nil {
+			return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+		} else if ok {
+			return proto.InputColumn{
+				Name: name,
+				Data: data,
+			}, fn, check, nil, nil
+		}
+	}
+
 	switch k := typ.Kind(); k {
 	case reflect.Uint8:
 		data = &proto.ColUInt8{}
-		fn = newAppender[uint8](idx)
+		fn = newKindAppender(idx, typ, uintValue[uint8])
+		direct = newFieldAppender[uint8](idx)
 	case reflect.Uint16:
 		data = &proto.ColUInt16{}
-		fn = newAppender[uint16](idx)
+		fn = newKindAppender(idx, typ, uintValue[uint16])
+		direct = newFieldAppender[uint16](idx)
 	case reflect.Uint32:
 		data = &proto.ColUInt32{}
-		fn = newAppender[uint32](idx)
+		fn = newKindAppender(idx, typ, uintValue[uint32])
+		direct = newFieldAppender[uint32](idx)
 	case reflect.Uint64:
 		data = &proto.ColUInt64{}
-		fn = newAppender[uint64](idx)
+		fn = newKindAppender(idx, typ, uintValue[uint64])
+		direct = newFieldAppender[uint64](idx)
 	case reflect.Uint:
 		switch s := strconv.IntSize; s {
 		case 64:
 			data = &proto.ColUInt64{}
-			fn = newUIntAppender[uint64](idx)
+			fn = newKindAppender(idx, typ, uintValue[uint64])
+			direct = newFieldAppender[uint64](idx)
 		case 32:
 			data = &proto.ColUInt32{}
-			fn = newUIntAppender[uint32](idx)
+			fn = newKindAppender(idx, typ, uintValue[uint32])
+			direct = newFieldAppender[uint32](idx)
 		default:
-			return col, nil, fmt.Errorf("unknown uint (has %d-bit)", s)
+			return col, nil, nil, nil, fmt.Errorf("unknown uint (has %d-bit)", s)
 		}
 
 	case reflect.Int8:
 		data = &proto.ColInt8{}
-		fn = newAppender[int8](idx)
+		fn = newKindAppender(idx, typ, intValue[int8])
+		direct = newFieldAppender[int8](idx)
 	case reflect.Int16:
 		data = &proto.ColInt16{}
-		fn = newAppender[int16](idx)
+		fn = newKindAppender(idx, typ, intValue[int16])
+		direct = newFieldAppender[int16](idx)
 	case reflect.Int32:
 		data = &proto.ColInt32{}
-		fn = newAppender[int32](idx)
+		fn = newKindAppender(idx, typ, intValue[int32])
+		direct = newFieldAppender[int32](idx)
 	case reflect.Int64:
 		data = &proto.ColInt64{}
-		fn = newAppender[int64](idx)
+		fn = newKindAppender(idx, typ, intValue[int64])
+		direct = newFieldAppender[int64](idx)
 	case reflect.Int:
 		switch s := strconv.IntSize; s {
 		case 64:
 			data = &proto.ColInt64{}
-			fn = newIntAppender[int64](idx)
+			fn = newKindAppender(idx, typ, intValue[int64])
+			direct = newFieldAppender[int64](idx)
 		case 32:
 			data = &proto.ColInt32{}
-			fn = newIntAppender[int32](idx)
+			fn = newKindAppender(idx, typ, intValue[int32])
+			direct = newFieldAppender[int32](idx)
 		default:
-			return col, nil, fmt.Errorf("unknown int (has %d-bit)", s)
+			return col, nil, nil, nil, fmt.Errorf("unknown int (has %d-bit)", s)
 		}
 
 	case reflect.Bool:
 		data = &proto.ColBool{}
-		fn = newAppender[bool](idx)
+		fn = newKindAppender(idx, typ, boolValue)
+		direct = newFieldAppender[bool](idx)
 	case reflect.Float32:
 		data = &proto.ColFloat32{}
-		fn = newAppender[float32](idx)
+		fn = newKindAppender(idx, typ, floatValue[float32])
+		direct = newFieldAppender[float32](idx)
 	case reflect.Float64:
 		data = &proto.ColFloat64{}
-		fn = newAppender[float64](idx)
+		fn = newKindAppender(idx, typ, floatValue[float64])
+		direct = newFieldAppender[float64](idx)
 	case reflect.String:
-		data = &proto.ColStr{}
-		fn = newAppender[string](idx)
+		size, ok, err := fixedStrSize(opts)
+		switch {
+		case err != nil:
+			return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+		case ok:
+			data = &proto.ColFixedStr{Size: size}
+			fn = newFixedStrAppender(idx, size)
+		case opts.Has("lowcardinality"):
+			data = proto.NewLowCardinality[string](&proto.ColStr{})
+			fn = newKindAppender(idx, typ, stringValue)
+			direct = newFieldAppender[string](idx)
+		default:
+			data = &proto.ColStr{}
+			fn = newKindAppender(idx, typ, stringValue)
+			direct = newFieldAppender[string](idx)
+		}
+	case reflect.Ptr:
+		if data, fn, check, err = getNullableColAndAppender(idx, tag.elem, typ.Elem(), ptrValue, naming); err != nil {
+			return col, nil, nil, nil, err
+		}
+	case reflect.Slice:
+		// []byte is a common representation of binary strings.
+		if typ.Elem() == byteType {
+			size, ok, err := fixedStrSize(opts)
+			switch {
+			case err != nil:
+				return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+			case ok:
+				data = &proto.ColFixedStr{Size: size}
+				fn = newFixedStrAppender(idx, size)
+			default:
+				data = &proto.ColStr{}
+				fn = newBytesAppender(idx, typ)
+				direct = newBytesFieldAppender(idx)
+			}
+			break
+		}
+
+		arr, err := newArrayColumn(tag.elem, typ.Elem(), naming)
+		if err != nil {
+			return col, nil, nil, nil, err
+		}
+
+		data = arr
+		fn = newArrayAppender(idx)
+		check = newArrayChecker(arr.Data.(*valueColumn).check)
+	case reflect.Array:
+		if typ.Elem() == byteType {
+			if typ.Len() == 0 {
+				return col, nil, nil, nil, fmt.Errorf("field %s: FixedString(0) is not supported", field.Name)
+			}
+
+			data = &proto.ColFixedStr{Size: typ.Len()}
+			fn = newByteArrayAppender(idx, typ.Len())
+			break
+		}
+
+		arr, err := newArrayColumn(tag.elem, typ.Elem(), naming)
+		if err != nil {
+			return col, nil, nil, nil, err
+		}
+
+		data = arr
+		fn = newArrayAppender(idx)
+		check = newArrayChecker(arr.Data.(*valueColumn).check)
+	case reflect.Map:
+		m, err := newMapColumn(tag.key, tag.value, typ.Key(), typ.Elem(), naming)
+		if err != nil {
+			return col, nil, nil, nil, err
+		}
+
+		data = m
+		fn = newMapAppender(idx, opts.Has("sorted"))
+		check = newMapChecker(m.Keys.(*valueColumn).check, m.Values.(*valueColumn).check)
 	}
 
 	switch typ.PkgPath() {
 	case "github.com/ClickHouse/ch-go/proto":
+		// Types like proto.Date are not appended as values of their kind.
+		direct = nil
 		switch name := typ.Name(); name {
 		case "Decimal32":
 			data = &proto.ColDecimal32{}
 			fn = newAppender[proto.Decimal32](idx)
+			direct = newFieldAppender[proto.Decimal32](idx)
 		case "Decimal64":
 			data = &proto.ColDecimal64{}
 			fn = newAppender[proto.Decimal64](idx)
+			direct = newFieldAppender[proto.Decimal64](idx)
 		case "Decimal128":
 			data = &proto.ColDecimal128{}
 			fn = newAppender[proto.Decimal128](idx)
+			direct = newFieldAppender[proto.Decimal128](idx)
 		case "Decimal256":
 			data = &proto.ColDecimal256{}
 			fn = newAppender[proto.Decimal256](idx)
+			direct = newFieldAppender[proto.Decimal256](idx)
 		case "UInt128":
 			data = &proto.ColUInt128{}
 			fn = newAppender[proto.UInt128](idx)
+			direct = newFieldAppender[proto.UInt128](idx)
 		case "UInt256":
 			data = &proto.ColUInt256{}
 			fn = newAppender[proto.UInt256](idx)
+			direct = newFieldAppender[proto.UInt256](idx)
 		case "Int128":
 			data = &proto.ColInt128{}
 			fn = newAppender[proto.Int128](idx)
+			direct = newFieldAppender[proto.Int128](idx)
 		case "Int256":
 			data = &proto.ColInt256{}
 			fn = newAppender[proto.Int256](idx)
+			direct = newFieldAppender[proto.Int256](idx)
 		case "Interval":
 			data = &proto.ColInterval{}
 			fn = newAppender[proto.Interval](idx)
+			direct = newFieldAppender[proto.Interval](idx)
 		case "IPv4":
 			data = &proto.ColIPv4{}
 			fn = newAppender[proto.IPv4](idx)
+			direct = newFieldAppender[proto.IPv4](idx)
 		case "IPv6":
 			data = &proto.ColIPv6{}
 			fn = newAppender[proto.IPv6](idx)
+			direct = newFieldAppender[proto.IPv6](idx)
 		case "Nothing":
 			data = new(proto.ColNothing)
 			fn = newAppender[proto.Nothing](idx)
 		case "Point":
 			data = &proto.ColPoint{}
 			fn = newAppender[proto.Point](idx)
+			direct = newFieldAppender[proto.Point](idx)
 		case "Date":
 			data = &proto.ColDate{}
 			fn = newDateAppender(idx, dateToTime[proto.Date])
@@ -165,42 +1136,124 @@ func getColAndAppenderFromField(name string, idx int, field reflect.StructField)
 			data = &proto.ColDate32{}
 			fn = newDateAppender(idx, dateToTime[proto.Date32])
 		case "DateTime":
-			data = &proto.ColDateTime{}
+			loc, err := timeLocation(opts)
+			if err != nil {
+				return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+
+			data = &proto.ColDateTime{Location: loc}
 			fn = newDateAppender(idx, dateToTime[proto.DateTime])
 		case "DateTime64":
+			loc, err := timeLocation(opts)
+			if err != nil {
+				return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+
+			// Values are in precision declared by tag, otherwise in the max precision.
+			precision, ok, err := dateTime64Precision(opts)
+			if err != nil {
+				return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+
 			data = &proto.ColDateTime64{
-				Precision:    proto.PrecisionMax,
+				Precision:    precision,
 				PrecisionSet: true,
+				Location:     loc,
+			}
+			if ok {
+				fn = newDateAppender(idx, func(t proto.DateTime64) time.Time {
+					return t.Time(precision)
+				})
+			} else {
+				fn = newDateAppender(idx, precisionDateToTime[proto.DateTime64])
 			}
-			fn = newDateAppender(idx, precisionDateToTime[proto.DateTime64])
 
 		default:
-			return col, nil, fmt.Errorf("field %s: ch type %s is not supported", field.Name, name)
+			return col, nil, nil, nil, fmt.Errorf("field %s: ch type %s is not supported", field.Name, name)
 		}
 	case "github.com/google/uuid":
 		if name := typ.Name(); name != "UUID" {
-			return col, nil, fmt.Errorf("field %s: uuid type %s is not supported", field.Name, name)
+			return col, nil, nil, nil, fmt.Errorf("field %s: uuid type %s is not supported", field.Name, name)
 		}
 
 		data = &proto.ColUUID{}
 		fn = newAppender[uuid.UUID](idx)
+		direct = newFieldAppender[uuid.UUID](idx)
 	case "time":
-		//TODO: add tag settings
-		if name := typ.Name(); name != "Time" {
-			return col, nil, fmt.Errorf("field %s: time type %s is not supported", field.Name, name)
+		direct = nil
+		switch name := typ.Name(); name {
+		case "Time":
+			loc, err := timeLocation(opts)
+			if err != nil {
+				return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+
+			if data, err = newTimeColumn(opts, loc); err != nil {
+				return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			fn = newTimeAppender(idx, loc)
+		case "Duration":
+			if data, fn, err = newDurationColumn(idx, opts); err != nil {
+				return col, nil, nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		default:
+			return col, nil, nil, nil, fmt.Errorf("field %s: time type %s is not supported", field.Name, name)
+		}
+	case "database/sql":
+		if !isSQLNullType(typ) {
+			return col, nil, nil, nil, fmt.Errorf("field %s: sql type %s is not supported", field.Name, typ.Name())
 		}
 
-		data = &proto.ColDateTime{}
-		fn = newAppender[time.Time](idx)
+		if data, fn, check, err = getNullableColAndAppender(idx, tag.elem, typ.Field(0).Type, sqlNullValue, naming); err != nil {
+			return col, nil, nil, nil, err
+		}
+	}
+
+	// Types which marshal themselves to text, e.g. netip.Prefix.
+	if data == nil {
+		data, fn, check, _ = getTextColAndAppender(idx, typ, opts)
+	}
+
+	if data == nil && isPlainStruct(typ) {
+		tuple, fields, err := newTupleColumn(field, typ, naming)
+		if err != nil {
+			return col, nil, nil, nil, err
+		}
+
+		data = tuple
+		fn = newTupleAppender(idx, fields)
+		check = newTupleChecker(tuple, fields)
 	}
 
 	if data == nil || fn == nil {
-		return col, nil, fmt.Errorf("field %s: unknown type %s.%s (kind of %s)",
+		return col, nil, nil, nil, fmt.Errorf("field %s: unknown type %s.%s (kind of %s)",
 			field.Name, typ.PkgPath(), typ.Name(), typ.Kind())
 	}
 
+	if enumBase != "" && !strings.Contains(string(data.Type()), string(enumBase)) {
+		return col, nil, nil, nil, fmt.Errorf("field %s: enum %s is not registered", field.Name, typ)
+	}
+
+	if opts.Has("lowcardinality") && !strings.Contains(string(data.Type()), string(proto.ColumnTypeLowCardinality)) {
+		return col, nil, nil, nil, fmt.Errorf("field %s: LowCardinality(%s) is not supported", field.Name, data.Type())
+	}
+
+	if opts.Has("decimal") && !strings.Contains(string(data.Type()), "Decimal(") {
+		return col, nil, nil, nil, fmt.Errorf("field %s: Decimal is not supported for %s", field.Name, data.Type())
+	}
+
+	optionTypes := append([]proto.ColumnType{proto.ColumnTypeIPv4, proto.ColumnTypeIPv6}, bigIntColumnTypes...)
+	for _, types := range geoTypes {
+		optionTypes = append(optionTypes, types...)
+	}
+	for _, typ := range optionTypes {
+		if opts.Has(strings.ToLower(string(typ))) && !strings.Contains(string(data.Type()), string(typ)) {
+			return col, nil, nil, nil, fmt.Errorf("field %s: %s is not supported for %s", field.Name, typ, data.Type())
+		}
+	}
+
 	return proto.InputColumn{
 		Name: name,
 		Data: data,
-	}, fn, nil
+	}, fn, check, direct, nil
 }
@@ -35,3 +35,61 @@ func TestDateTime64Converter(t *testing.T) {
 	date := proto.ToDateTime64(tdate, proto.PrecisionMax)
 	assert.Equal(t, tdate, precisionDateToTime(date))
 }
+
+type testTimes struct {
+	Default time.Time
+	Date    time.Time        `ch:"date,date,tz=Asia/Tokyo"`
+	Date32  time.Time        `ch:"date32,date32"`
+	UTC     time.Time        `ch:"utc,tz=UTC"`
+	Milli   time.Time        `ch:"milli,datetime64=3,tz=Europe/Moscow"`
+	Micro   proto.DateTime64 `ch:"micro,datetime64=6"`
+}
+
+func TestTimeColumns(t *testing.T) {
+	b, err := newBatch[testTimes]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	ts := time.Date(2022, time.October, 12, 20, 30, 45, 123456789, time.UTC)
+	b.append(testTimes{
+		Default: ts,
+		Date:    ts,
+		Date32:  ts,
+		UTC:     ts,
+		Milli:   ts,
+		Micro:   proto.ToDateTime64(ts, proto.PrecisionMicro),
+	})
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"DateTime",
+		"Date",
+		"Date32",
+		"DateTime('UTC')",
+		"DateTime64(3, 'Europe/Moscow')",
+		"DateTime64(6)",
+	}, types)
+
+	// 2022-10-13 in Tokyo
+	assert.Equal(t, proto.NewDate(2022, time.October, 13), (*b.input[1].Data.(*proto.ColDate))[0])
+	assert.Equal(t, proto.ToDateTime64(ts, proto.PrecisionMilli), b.input[4].Data.(*proto.ColDateTime64).Data[0])
+	assert.Equal(t, proto.ToDateTime64(ts, proto.PrecisionMicro), b.input[5].Data.(*proto.ColDateTime64).Data[0])
+}
+
+func TestTimeColumnsInvalidOptions(t *testing.T) {
+	_, err := newBatch[struct {
+		T time.Time `ch:"t,date,datetime64=3"`
+	}]()
+	assert.NotNil(t, err)
+
+	_, err = newBatch[struct {
+		T time.Time `ch:"t,datetime64=10"`
+	}]()
+	assert.NotNil(t, err)
+
+	_, err = newBatch[struct {
+		T time.Time `ch:"t,tz=Unknown/Location"`
+	}]()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,73 @@
+package chdistr
+
+import (
+	"sync"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+// ColumnMarshaler is implemented by types which encode themselves
+// into ch-go column. It takes precedence over the built-in mappings.
+// Pointers to implementing types are mapped to Nullable columns.
+type ColumnMarshaler interface {
+	// NewColumn returns new empty column for values of the type.
+	// It is called on zero value.
+	NewColumn() proto.ColInput
+	// AppendColumn appends the value to column created by NewColumn.
+	AppendColumn(col proto.ColInput)
+}
+
+var columnMarshalerType = reflect.TypeOf((*ColumnMarshaler)(nil)).Elem()
+
+type customType struct {
+	newColumn   func() proto.ColInput
+	appendValue func(v any, col proto.ColInput)
+}
+
+var customTypes sync.Map // map[reflect.Type]customType
+
+// RegisterType registers column mapping for values of type V.
+// Registered mapping takes precedence over ColumnMarshaler and the built-in mappings,
+// so it also can be used to override them.
+//
+// newColumn is called for each batch and must return new empty column,
+// appendValue appends value to the column returned by newColumn.
+func RegisterType[V any](newColumn func() proto.ColInput, appendValue func(v V, col proto.ColInput)) {
+	customTypes.Store(reflect.TypeOf((*V)(nil)).Elem(), customType{
+		newColumn: newColumn,
+		appendValue: func(v any, col proto.ColInput) {
+			appendValue(v.(V), col)
+		},
+	})
+}
+
+// getCustomColAndAppender returns column and appender for type registered with RegisterType
+// or implementing ColumnMarshaler.
+func getCustomColAndAppender(idx int, typ reflect.Type) (proto.ColInput, appender, bool) {
+	if v, ok := customTypes.Load(typ); ok {
+		custom := v.(customType)
+		return custom.newColumn(), func(v any, input proto.Input) {
+			custom.appendValue(v, input[idx].Data)
+		}, true
+	}
+
+	switch {
+	case typ.Kind() == reflect.Ptr:
+		// Pointers are Nullable, AppendColumn can't be called on nil.
+		return nil, nil, false
+	case typ.Implements(columnMarshalerType):
+		return reflect.Zero(typ).Interface().(ColumnMarshaler).NewColumn(), func(v any, input proto.Input) {
+			v.(ColumnMarshaler).AppendColumn(input[idx].Data)
+		}, true
+	case reflect.PtrTo(typ).Implements(columnMarshalerType):
+		// Value is copied to call methods with pointer receiver.
+		return reflect.New(typ).Interface().(ColumnMarshaler).NewColumn(), func(v any, input proto.Input) {
+			ptr := reflect.New(typ)
+			ptr.Elem().Set(reflect.ValueOf(v))
+			ptr.Interface().(ColumnMarshaler).AppendColumn(input[idx].Data)
+		}, true
+	}
+
+	return nil, nil, false
+}
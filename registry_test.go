@@ -0,0 +1,112 @@
+package chdistr
+
+import (
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMoney is amount in cents.
+type testMoney struct {
+	cents int64
+}
+
+// testGeoHash implements ColumnMarshaler with value receiver.
+type testGeoHash struct {
+	lat, lon float64
+}
+
+func (testGeoHash) NewColumn() proto.ColInput {
+	return &proto.ColStr{}
+}
+
+func (h testGeoHash) AppendColumn(col proto.ColInput) {
+	hash := "s"
+	if h.lat > 0 {
+		hash = "u"
+	}
+	col.(*proto.ColStr).Append(hash)
+}
+
+// testID implements ColumnMarshaler with pointer receiver.
+type testID struct {
+	id uint64
+}
+
+func (*testID) NewColumn() proto.ColInput {
+	return &proto.ColUInt64{}
+}
+
+func (i *testID) AppendColumn(col proto.ColInput) {
+	col.(*proto.ColUInt64).Append(i.id)
+}
+
+type testCustom struct {
+	Price  testMoney
+	Prices []testMoney
+	Hash   testGeoHash
+	ID     testID
+	IDPtr  *testID
+}
+
+func TestBatchCustomTypes(t *testing.T) {
+	RegisterType(func() proto.ColInput {
+		return &proto.ColDecimal64{}
+	}, func(v testMoney, col proto.ColInput) {
+		col.(*proto.ColDecimal64).Append(proto.Decimal64(v.cents))
+	})
+
+	b, err := newBatch[testCustom]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b.append(testCustom{
+		Price:  testMoney{cents: 150},
+		Prices: []testMoney{{1}, {2}},
+		Hash:   testGeoHash{lat: 1},
+		ID:     testID{id: 7},
+		IDPtr:  &testID{id: 8},
+	})
+	b.append(testCustom{})
+
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []proto.ColumnType{
+		"Decimal64",
+		"Array(Decimal64)",
+		"String",
+		"UInt64",
+		"Nullable(UInt64)",
+	}, types)
+
+	assert.Equal(t, proto.Decimal64(150), b.input[0].Data.(*proto.ColDecimal64).Row(0))
+	prices := b.input[1].Data.(*proto.ColArr[reflect.Value]).Data.(*valueColumn).ColInput.(*proto.ColDecimal64)
+	assert.Equal(t, []proto.Decimal64{1, 2}, []proto.Decimal64(*prices))
+	assert.Equal(t, "u", b.input[2].Data.(*proto.ColStr).Row(0))
+	assert.Equal(t, uint64(7), b.input[3].Data.(*proto.ColUInt64).Row(0))
+
+	idPtr := b.input[4].Data.(*nullableColumn)
+	assert.Equal(t, []uint8{0, 1}, []uint8(idPtr.Nulls))
+	assert.Equal(t, uint64(8), idPtr.Values.(*valueColumn).ColInput.(*proto.ColUInt64).Row(0))
+}
+
+func TestBatchCustomTypeNullable(t *testing.T) {
+	b, err := newBatch[struct {
+		Hash *testGeoHash
+	}]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	b.append(struct{ Hash *testGeoHash }{Hash: &testGeoHash{lat: 1}})
+	b.append(struct{ Hash *testGeoHash }{})
+
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []proto.ColumnType{"Nullable(String)"}, types)
+
+	col := b.input[0].Data.(*nullableColumn)
+	assert.Equal(t, []uint8{0, 1}, []uint8(col.Nulls))
+	assert.Equal(t, "u", col.Values.(*valueColumn).ColInput.(*proto.ColStr).Row(0))
+}
@@ -0,0 +1,213 @@
+package chdistr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ClickHouse/ch-go"
+	"github.com/ClickHouse/ch-go/chpool"
+	"github.com/ClickHouse/ch-go/proto"
+)
+
+var ErrSchemaMismatch = errors.New("schema mismatch")
+
+// tableColumn is the column of table described by server.
+type tableColumn struct {
+	name string
+	typ  proto.ColumnType
+	// defaultKind is DEFAULT, MATERIALIZED, ALIAS or EPHEMERAL, empty for plain columns.
+	defaultKind string
+}
+
+// describeTable returns columns of table by DESCRIBE TABLE query.
+func describeTable(ctx context.Context, client *chpool.Pool, table string) ([]tableColumn, error) {
+	var (
+		results proto.Results
+		columns []tableColumn
+	)
+	err := client.Do(ctx, ch.Query{
+		Body:   "DESCRIBE TABLE " + strconv.QuoteToASCII(table),
+		Result: results.Auto(),
+		OnResult: func(ctx context.Context, block proto.Block) error {
+			names, types := resultStr(results, "name"), resultStr(results, "type")
+			if names == nil || types == nil {
+				return errors.New("unexpected DESCRIBE TABLE result")
+			}
+
+			defaultKinds := resultStr(results, "default_type")
+			for i := 0; i < names.Rows(); i++ {
+				col := tableColumn{
+					name: names.Row(i),
+					typ:  proto.ColumnType(types.Row(i)),
+				}
+				if defaultKinds != nil {
+					col.defaultKind = defaultKinds.Row(i)
+				}
+				columns = append(columns, col)
+			}
+
+			for _, col := range results {
+				col.Data.Reset()
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+func resultStr(results proto.Results, name string) *proto.ColStr {
+	for _, col := range results {
+		if col.Name == name {
+			s, _ := col.Data.(*proto.ColStr)
+			return s
+		}
+	}
+	return nil
+}
+
+// diffSchema compares columns of batch input with columns of table. It reports
+// extra columns of row which are not in table (or can't be inserted), missing columns of table
+// which are not set by row (columns with default expressions are skipped) and type mismatches.
+func diffSchema(input proto.Input, table []tableColumn) []string {
+	columns := make(map[string]tableColumn, len(table))
+	for _, col := range table {
+		columns[col.name] = col
+	}
+
+	var diff []string
+	inserted := make(map[string]struct{}, len(input))
+	for _, col := range input {
+		inserted[col.Name] = struct{}{}
+		t := col.Data.Type()
+
+		tableCol, ok := columns[col.Name]
+		switch {
+		case !ok:
+			diff = append(diff, fmt.Sprintf("extra column %s %s: not in table", col.Name, t))
+		case tableCol.defaultKind == "MATERIALIZED" || tableCol.defaultKind == "ALIAS":
+			diff = append(diff, fmt.Sprintf("extra column %s %s: %s column can't be inserted", col.Name, t, tableCol.defaultKind))
+		case !sameColumnType(t, tableCol.typ):
+			diff = append(diff, fmt.Sprintf("column %s: type %s doesn't match table type %s", col.Name, t, tableCol.typ))
+		}
+	}
+
+	for _, col := range table {
+		if _, ok := inserted[col.name]; !ok && col.defaultKind == "" {
+			diff = append(diff, fmt.Sprintf("missing column %s %s: not set by row", col.name, col.typ))
+		}
+	}
+	return diff
+}
+
+// sameColumnType reports whether values of column type t are inserted to table column
+// of type tableType as is. Server converts columns to LowCardinality and DateTime
+// columns of any time zone, so these differences are ignored. Codes of enums are taken
+// from the table, so enum matches table enum which has all its names.
+func sameColumnType(t, tableType proto.ColumnType) bool {
+	return sameInsertedType(proto.ColumnType(insertedType(t)), proto.ColumnType(insertedType(tableType)))
+}
+
+func sameInsertedType(t, tableType proto.ColumnType) bool {
+	name, base, args := splitNamedType(t)
+	tableName, tableBase, tableArgs := splitNamedType(tableType)
+	switch {
+	case name != tableName || base != tableBase:
+		return normalizeType(t) == normalizeType(tableType)
+	case base == proto.ColumnTypeEnum8 || base == proto.ColumnTypeEnum16:
+		tableNames := make(map[string]struct{}, len(tableArgs))
+		for _, name := range enumNames(tableType) {
+			tableNames[name] = struct{}{}
+		}
+
+		for _, name := range enumNames(t) {
+			if _, ok := tableNames[name]; !ok {
+				return false
+			}
+		}
+		return true
+	case len(args) == 0 || len(args) != len(tableArgs):
+		return normalizeType(t) == normalizeType(tableType)
+	}
+
+	for i := range args {
+		if !sameInsertedType(args[i], tableArgs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// insertedType removes LowCardinality and time zones of DateTime types, e.g.
+// Array(LowCardinality(String)) to Array(String) and DateTime64(3, 'UTC') to DateTime64(3).
+func insertedType(t proto.ColumnType) string {
+	name, base, args := splitNamedType(t)
+
+	switch base {
+	case proto.ColumnTypeLowCardinality:
+		if len(args) == 1 {
+			return name + insertedType(args[0])
+		}
+	case proto.ColumnTypeDateTime:
+		return name + string(base)
+	case proto.ColumnTypeDateTime64:
+		if len(args) != 0 {
+			return name + string(base.With(string(args[0])))
+		}
+	case proto.ColumnTypeEnum8, proto.ColumnTypeEnum16:
+		return string(t)
+	}
+
+	if len(args) == 0 {
+		return string(t)
+	}
+
+	elems := make([]string, len(args))
+	for i, arg := range args {
+		elems[i] = insertedType(arg)
+	}
+	return name + string(base) + "(" + strings.Join(elems, ",") + ")"
+}
+
+// splitNamedType splits type like splitType. Elements of named Tuple are prefixed
+// by names, e.g. Tuple(name LowCardinality(String)), the name is returned with trailing space.
+func splitNamedType(t proto.ColumnType) (string, proto.ColumnType, []proto.ColumnType) {
+	base, args := splitType(t)
+	if i := strings.LastIndexByte(string(base), ' '); i != -1 && len(args) != 0 {
+		return string(base[:i+1]), base[i+1:], args
+	}
+	return "", base, args
+}
+
+// enumNames returns names of elements of Enum8 or Enum16 type, e.g. [a b] of Enum8('a' = 1, 'b' = 2).
+func enumNames(t proto.ColumnType) []string {
+	s := string(t)
+	s = s[strings.IndexByte(s, '(')+1 : strings.LastIndexByte(s, ')')]
+
+	var names []string
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\'' {
+			continue
+		}
+
+		var name strings.Builder
+		for i++; i < len(s) && s[i] != '\''; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			name.WriteByte(s[i])
+		}
+		names = append(names, name.String())
+
+		// Skip code of element.
+		for i < len(s) && s[i] != ',' {
+			i++
+		}
+	}
+	return names
+}
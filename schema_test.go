@@ -0,0 +1,94 @@
+package chdistr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSchema struct {
+	ID      uint64
+	Name    string    `ch:",lowcardinality"`
+	Tags    []string  `ch:",type=Array(LowCardinality(String))"`
+	Created time.Time `ch:",datetime64=3"`
+	Amount  float64   `ch:",decimal(18,4)"`
+	Score   *float32
+	Extra   string
+	Total   uint64
+}
+
+func TestDiffSchema(t *testing.T) {
+	b, err := newBatch[testSchema]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	table := []tableColumn{
+		{name: "id", typ: "UInt64"},
+		{name: "name", typ: "String"},
+		{name: "tags", typ: "Array(LowCardinality(String))"},
+		{name: "created", typ: "DateTime64(3, 'UTC')"},
+		{name: "amount", typ: "Decimal(18, 4)"},
+		{name: "score", typ: "Nullable(Float64)"},
+		{name: "total", typ: "UInt64", defaultKind: "MATERIALIZED"},
+		{name: "host", typ: "String"},
+		{name: "inserted_at", typ: "DateTime", defaultKind: "DEFAULT"},
+	}
+
+	assert.Equal(t, []string{
+		"column score: type Nullable(Float32) doesn't match table type Nullable(Float64)",
+		"extra column extra String: not in table",
+		"extra column total UInt64: MATERIALIZED column can't be inserted",
+		"missing column host String: not set by row",
+	}, diffSchema(b.input, table))
+
+	table = []tableColumn{
+		{name: "id", typ: "UInt64"},
+		{name: "name", typ: "LowCardinality(String)"},
+		{name: "tags", typ: "Array(String)"},
+		{name: "created", typ: "DateTime64(3)"},
+		{name: "amount", typ: "Decimal64(4)"},
+		{name: "score", typ: "Nullable(Float32)"},
+		{name: "extra", typ: "String"},
+		{name: "total", typ: "UInt64"},
+	}
+	assert.Empty(t, diffSchema(b.input, table))
+}
+
+func TestInsertedType(t *testing.T) {
+	for typ, expected := range map[proto.ColumnType]string{
+		"String":                                   "String",
+		"LowCardinality(Nullable(String))":         "Nullable(String)",
+		"Map(LowCardinality(String), UInt64)":      "Map(String,UInt64)",
+		"Nullable(DateTime('Europe/Moscow'))":      "Nullable(DateTime)",
+		"DateTime64(6, 'UTC')":                     "DateTime64(6)",
+		"Tuple(a LowCardinality(String), b UInt8)": "Tuple(a String,b UInt8)",
+		"Enum8('a' = 1, 'b' = 2)":                  "Enum8('a' = 1, 'b' = 2)",
+	} {
+		assert.Equal(t, expected, insertedType(typ), typ)
+	}
+
+	assert.True(t, sameColumnType("Nullable(Decimal(18, 4))", "Nullable(Decimal64(4))"))
+	assert.False(t, sameColumnType("Array(UInt8)", "Array(UInt16)"))
+}
+
+func TestSameEnumType(t *testing.T) {
+	for _, tc := range []struct {
+		t, tableType proto.ColumnType
+		same         bool
+	}{
+		{t: "Enum8('a'=1,'b'=2)", tableType: "Enum8('a' = 1, 'b' = 2)", same: true},
+		{t: "Enum8('a' = 1)", tableType: "Enum8('a' = 1, 'b' = 2)", same: true},
+		{t: "Enum8('active' = 1, 'deleted' = 2)", tableType: "Enum8('deleted' = 1, 'active' = 2)", same: true},
+		{t: "Array(Enum8('a' = 1))", tableType: "Array(Enum8('b' = 1, 'a' = 2))", same: true},
+		{t: "Tuple(s Enum8('a' = 1))", tableType: "Tuple(s Enum8('a' = 2))", same: true},
+		{t: "Enum8('it\\'s' = 1)", tableType: "Enum8('it\\'s' = 3, 'b' = 2)", same: true},
+		{t: "Enum8('a' = 1, 'c' = 2)", tableType: "Enum8('a' = 1, 'b' = 2)", same: false},
+		{t: "Enum8('a' = 1)", tableType: "Enum16('a' = 1)", same: false},
+		{t: "Tuple(s Enum8('a' = 1))", tableType: "Tuple(t Enum8('a' = 1))", same: false},
+	} {
+		assert.Equal(t, tc.same, sameColumnType(tc.t, tc.tableType), "%s, %s", tc.t, tc.tableType)
+	}
+}
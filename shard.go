@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/ClickHouse/ch-go"
 	"github.com/ClickHouse/ch-go/chpool"
+	"github.com/ClickHouse/ch-go/proto"
 	"go.uber.org/multierr"
 )
 
@@ -16,6 +18,9 @@ type shard[T any] struct {
 	client *chpool.Pool
 	host   Host
 	pool   batchPool[T]
+
+	// errHandler receives errors of rows which are appended with values replaced by null.
+	errHandler func(err error)
 }
 
 func (s *shard[T]) start(
@@ -95,7 +100,9 @@ loop:
 	for {
 		select {
 		case v := <-data:
-			b.append(v)
+			if err := b.append(v); err != nil && s.errHandler != nil {
+				s.errHandler(fmt.Errorf("host %s: %w", s.host.Info(), err))
+			}
 		case sharedBatch := <-sharedBatches:
 			execQuery(sharedBatch)
 		case <-t.C:
@@ -121,13 +128,26 @@ loop:
 	return err
 }
 
+// checkSchema compares columns of input with columns of table on the host.
+func (s *shard[T]) checkSchema(ctx context.Context, table string, input proto.Input) error {
+	columns, err := describeTable(ctx, s.client, table)
+	if err != nil {
+		return fmt.Errorf("host %s: describe table %s: %w", s.host.Info(), table, err)
+	}
+
+	if diff := diffSchema(input, columns); len(diff) != 0 {
+		return fmt.Errorf("host %s: %w of table %s:\n\t%s", s.host.Info(), ErrSchemaMismatch, table, strings.Join(diff, "\n\t"))
+	}
+	return nil
+}
+
 func (s *shard[T]) close() error {
 	s.client.Close()
 
 	return nil
 }
 
-func newShard[T any](ctx context.Context, host Host, opt ch.Options) (*shard[T], error) {
+func newShard[T any](ctx context.Context, host Host, opt ch.Options, naming columnNaming) (*shard[T], error) {
 	client, err := chpool.Dial(ctx, chpool.Options{
 		ClientOptions: opt,
 		MinConns:      1,
@@ -138,31 +158,37 @@ func newShard[T any](ctx context.Context, host Host, opt ch.Options) (*shard[T],
 	}
 
 	// Generic checking
-	if _, err := newBatch[T](); err != nil {
+	if _, err := newBatchWithNaming[T](naming); err != nil {
 		return nil, fmt.Errorf("batch init: %w", err)
 	}
 
 	return &shard[T]{
-		pool:   make(batchPool[T], 4),
+		pool: batchPool[T]{
+			batches: make(chan *batch[T], 4),
+			naming:  naming,
+		},
 		client: client,
 		host:   host,
 	}, nil
 }
 
-type batchPool[T any] chan *batch[T]
+type batchPool[T any] struct {
+	batches chan *batch[T]
+	naming  columnNaming
+}
 
 func (p batchPool[T]) get() (*batch[T], error) {
 	select {
-	case b := <-p:
+	case b := <-p.batches:
 		return b, nil
 	default:
-		return newBatch[T]()
+		return newBatchWithNaming[T](p.naming)
 	}
 }
 
 func (p batchPool[T]) put(b *batch[T]) {
 	select {
-	case p <- b:
+	case p.batches <- b:
 		return
 	default:
 		return
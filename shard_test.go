@@ -15,7 +15,7 @@ import (
 
 type testStruct struct {
 	Ts   time.Time
-	Ts6  proto.DateTime64
+	Ts6  proto.DateTime64 `ch:"ts6,datetime64=6"`
 	Foo  string
 	Bar  uint8
 	Long proto.UInt256
@@ -66,7 +66,7 @@ func TestShard(t *testing.T) {
 	sh, err := newShard[testStruct](ctx, NewHostInfo("127.0.0.1:9000", "default"), ch.Options{
 		Address:  "127.0.0.1:9000",
 		Database: "default",
-	})
+	}, columnNaming{})
 	if err != nil {
 		t.Fatal("create shard: ", err)
 	}
@@ -0,0 +1,220 @@
+package chdistr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+// tagOptions is the comma-separated list of options following the column name
+// in the ch struct tag, e.g. `ch:"attrs,sorted"`.
+//
+// Option is a flag (`sorted`), a key-value pair (`tz=UTC`)
+// or has arguments in parentheses (`decimal(18,4)`).
+type tagOptions map[string]string
+
+// parseTag splits the ch struct tag into column name and options.
+func parseTag(tag string) (string, tagOptions) {
+	parts := splitTopLevel(tag)
+	opts := make(tagOptions, len(parts)-1)
+	for _, opt := range parts[1:] {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			continue
+		}
+
+		if i := strings.IndexAny(opt, "=("); i != -1 {
+			key, val := opt[:i], opt[i+1:]
+			if opt[i] == '(' {
+				val = strings.TrimSuffix(val, ")")
+			}
+			opts[strings.TrimSpace(key)] = strings.TrimSpace(val)
+			continue
+		}
+
+		opts[opt] = ""
+	}
+
+	return strings.TrimSpace(parts[0]), opts
+}
+
+func (o tagOptions) Has(opt string) bool {
+	_, ok := o[opt]
+	return ok
+}
+
+func (o tagOptions) Get(opt string) (string, bool) {
+	val, ok := o[opt]
+	return val, ok
+}
+
+// splitTopLevel splits s by commas which are not enclosed in parentheses.
+func splitTopLevel(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// tagOptionKinds are kinds of fields which tag option applies to: field types, which are
+// matched with the field type and its elements, or types of the field column, which are
+// matched by name in the column type, e.g. Map in Map(String, UInt64).
+type tagOptionKinds struct {
+	any     bool
+	types   []reflect.Type
+	columns []string
+}
+
+// knownTagOptions are options of ch tag. The type option is checked with the column type
+// and nested with the field type where they are handled. The prefix option applies to
+// embedded structs only, which have no columns.
+var knownTagOptions = map[string]tagOptionKinds{
+	"type":   {any: true},
+	"nested": {any: true},
+	"prefix": {},
+
+	"lowcardinality": {columns: []string{"LowCardinality"}},
+	"sorted":         {columns: []string{"Map"}},
+	"stringer":       {columns: []string{"String"}},
+	"json":           {columns: []string{"String", "JSON", "Object"}},
+	"fixedstring":    {columns: []string{"FixedString"}},
+	"enum8":          {columns: []string{"Enum8"}},
+	"enum16":         {columns: []string{"Enum16"}},
+	"decimal":        {columns: []string{"Decimal"}},
+
+	"date":       {columns: []string{"Date"}},
+	"date32":     {columns: []string{"Date32"}},
+	"datetime":   {columns: []string{"DateTime"}},
+	"datetime64": {columns: []string{"DateTime64"}},
+	"tz":         {columns: []string{"Date", "Date32", "DateTime", "DateTime64"}},
+
+	"ns":       {types: []reflect.Type{durationType}},
+	"us":       {types: []reflect.Type{durationType}},
+	"ms":       {types: []reflect.Type{durationType}},
+	"s":        {types: []reflect.Type{durationType}},
+	"interval": {types: []reflect.Type{durationType}},
+
+	"ipv4":    {columns: []string{"IPv4"}},
+	"ipv6":    {columns: []string{"IPv6"}},
+	"int128":  {columns: []string{"Int128"}},
+	"int256":  {columns: []string{"Int256"}},
+	"uint128": {columns: []string{"UInt128"}},
+	"uint256": {columns: []string{"UInt256"}},
+
+	"ring":            {columns: []string{"Ring"}},
+	"linestring":      {columns: []string{"LineString"}},
+	"polygon":         {columns: []string{"Polygon"}},
+	"multilinestring": {columns: []string{"MultiLineString"}},
+	"multipolygon":    {columns: []string{"MultiPolygon"}},
+}
+
+// checkTagOptions returns error for unknown options of field tag
+// and options which don't apply to the field type or its column type t.
+func checkTagOptions(field reflect.StructField, t proto.ColumnType) error {
+	_, opts := parseTag(field.Tag.Get("ch"))
+	names := columnTypeNames(t)
+
+	// Options are sorted to report the same error for the same tag.
+	keys := make([]string, 0, len(opts))
+	for opt := range opts {
+		keys = append(keys, opt)
+	}
+	sort.Strings(keys)
+
+	for _, opt := range keys {
+		kinds, ok := knownTagOptions[opt]
+		if !ok {
+			return fmt.Errorf("field %s: unknown tag option %q", field.Name, opt)
+		}
+
+		if !kinds.appliesTo(field.Type, names) {
+			return fmt.Errorf("field %s: tag option %s is not supported for %s of type %s", field.Name, opt, t, field.Type)
+		}
+	}
+	return nil
+}
+
+func (k tagOptionKinds) appliesTo(typ reflect.Type, names map[string]struct{}) bool {
+	if k.any {
+		return true
+	}
+
+	for _, target := range k.types {
+		if hasElemType(typ, target) {
+			return true
+		}
+	}
+
+	for _, name := range k.columns {
+		if _, ok := names[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasElemType reports whether typ is target or has elements of target type
+// through pointers, slices, arrays and maps.
+func hasElemType(typ, target reflect.Type) bool {
+	for {
+		if typ == target {
+			return true
+		}
+
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			typ = typ.Elem()
+		case reflect.Map:
+			return hasElemType(typ.Key(), target) || hasElemType(typ.Elem(), target)
+		default:
+			return false
+		}
+	}
+}
+
+// columnTypeNames returns names of types in column type t, e.g. Array, Nullable and String
+// of Array(Nullable(String)). Quoted arguments like names of enum values are skipped.
+func columnTypeNames(t proto.ColumnType) map[string]struct{} {
+	names := make(map[string]struct{})
+	var (
+		quoted bool
+		start  = -1
+	)
+	for i, r := range string(t) + " " {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case !quoted && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'):
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+
+		if start != -1 {
+			names[string(t)[start:i]] = struct{}{}
+			start = -1
+		}
+	}
+	return names
+}
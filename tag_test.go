@@ -0,0 +1,26 @@
+package chdistr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTag(t *testing.T) {
+	for _, tc := range []struct {
+		tag  string
+		name string
+		opts tagOptions
+	}{
+		{"", "", tagOptions{}},
+		{"foo", "foo", tagOptions{}},
+		{"foo,sorted", "foo", tagOptions{"sorted": ""}},
+		{",sorted", "", tagOptions{"sorted": ""}},
+		{"ts, tz=UTC ,datetime64=3", "ts", tagOptions{"tz": "UTC", "datetime64": "3"}},
+		{"price,decimal(18,4)", "price", tagOptions{"decimal": "18,4"}},
+	} {
+		name, opts := parseTag(tc.tag)
+		assert.Equal(t, tc.name, name, tc.tag)
+		assert.Equal(t, tc.opts, opts, tc.tag)
+	}
+}
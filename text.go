@@ -0,0 +1,70 @@
+package chdistr
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/goccy/go-reflect"
+)
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+type textFormatter func(v any) ([]byte, error)
+
+// getTextFormatter returns formatter of values of typ by encoding.TextMarshaler,
+// or by fmt.Stringer if stringer is set. Methods with pointer receiver are called on copy of value.
+func getTextFormatter(typ reflect.Type, stringer bool) (converter[string], bool) {
+	iface := textMarshalerType
+	if stringer {
+		iface = stringerType
+	}
+
+	var ptr bool
+	switch {
+	case typ.Implements(iface):
+	case typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(iface):
+		ptr = true
+	default:
+		return nil, false
+	}
+
+	return func(v any) (string, error) {
+		if ptr {
+			p := reflect.New(typ)
+			p.Elem().Set(reflect.ValueOf(v))
+			v = p.Interface()
+		}
+
+		if stringer {
+			return v.(fmt.Stringer).String(), nil
+		}
+
+		b, err := v.(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}, true
+}
+
+// getTextColAndAppender returns String column for types implementing encoding.TextMarshaler,
+// or fmt.Stringer if stringer tag option is set. Marshal errors are reported by checker.
+func getTextColAndAppender(idx int, typ reflect.Type, opts tagOptions) (proto.ColInput, appender, checker, bool) {
+	stringer := opts.Has("stringer")
+	format, ok := getTextFormatter(typ, stringer)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	fn, check := newConvertingAppender(idx, format)
+	if stringer {
+		// String doesn't fail.
+		check = nil
+	}
+
+	if opts.Has("lowcardinality") {
+		return proto.NewLowCardinality[string](&proto.ColStr{}), fn, check, true
+	}
+	return &proto.ColStr{}, fn, check, true
+}
@@ -0,0 +1,107 @@
+package chdistr
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// testVersion implements encoding.TextMarshaler with pointer receiver.
+type testVersion struct {
+	Major, Minor int
+}
+
+func (v *testVersion) MarshalText() ([]byte, error) {
+	if v.Major < 0 {
+		return nil, errors.New("negative major")
+	}
+	return []byte{byte('0' + v.Major), '.', byte('0' + v.Minor)}, nil
+}
+
+// testColor implements fmt.Stringer.
+type testColor uint8
+
+func (c testColor) String() string {
+	return [...]string{"red", "green"}[c]
+}
+
+type testText struct {
+	Prefix  netip.Prefix
+	Version testVersion
+	Color   testColor `ch:"color,stringer,lowcardinality"`
+	Code    testColor
+	Opt     *netip.Prefix
+}
+
+func TestBatchText(t *testing.T) {
+	b, err := newBatch[testText]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	row := testText{
+		Prefix:  netip.MustParsePrefix("10.0.0.0/8"),
+		Version: testVersion{1, 2},
+		Color:   1,
+		Code:    1,
+	}
+	if !assert.Nil(t, b.check(row)) {
+		return
+	}
+	b.append(row)
+
+	types := columnTypes(t, b.input, 1)
+	assert.Equal(t, []proto.ColumnType{
+		"String",
+		"String",
+		"LowCardinality(String)",
+		"UInt8",
+		"Nullable(String)",
+	}, types)
+
+	assert.Equal(t, "10.0.0.0/8", b.input[0].Data.(*proto.ColStr).Row(0))
+	assert.Equal(t, "1.2", b.input[1].Data.(*proto.ColStr).Row(0))
+	assert.Equal(t, "green", b.input[2].Data.(*proto.ColLowCardinality[string]).Row(0))
+
+	row.Version.Major = -1
+	assert.ErrorIs(t, b.check(row), ErrRowRejected)
+}
+
+func TestBatchNotStringer(t *testing.T) {
+	_, err := newBatch[struct {
+		V int `ch:"v,stringer"`
+	}]()
+	assert.NotNil(t, err)
+}
+
+type testStringerPtr struct {
+	Color  *testColor `ch:"color,stringer"`
+	Colors *testColor `ch:"colors,stringer,lowcardinality"`
+}
+
+func TestBatchStringerPointer(t *testing.T) {
+	b, err := newBatch[testStringerPtr]()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	green := testColor(1)
+	b.append(testStringerPtr{Color: &green, Colors: &green})
+	b.append(testStringerPtr{})
+
+	types := columnTypes(t, b.input, 2)
+	assert.Equal(t, []proto.ColumnType{
+		"Nullable(String)",
+		"LowCardinality(Nullable(String))",
+	}, types)
+
+	color := b.input[0].Data.(*nullableColumn)
+	assert.Equal(t, []uint8{0, 1}, []uint8(color.Nulls))
+	assert.Equal(t, "green", color.Values.(*valueColumn).ColInput.(*proto.ColStr).Row(0))
+
+	colors := b.input[1].Data.(*colLowCardinalityNullable[string])
+	assert.Equal(t, []proto.Nullable[string]{proto.NewNullable("green"), {}}, colors.Values)
+}